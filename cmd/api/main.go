@@ -1,11 +1,17 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"slices"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -14,60 +20,336 @@ import (
 	"github.com/ab-dauletkhan/coc/internal/catalog"
 	"github.com/ab-dauletkhan/coc/internal/coc"
 	"github.com/ab-dauletkhan/coc/internal/config"
+	"github.com/ab-dauletkhan/coc/internal/logging"
+	"github.com/ab-dauletkhan/coc/internal/metrics"
+	"github.com/ab-dauletkhan/coc/internal/ratelimit"
+	"github.com/ab-dauletkhan/coc/internal/tracing"
 
 	primaryhttp "github.com/ab-dauletkhan/coc/internal/adapters/primary/http"
 	secondary "github.com/ab-dauletkhan/coc/internal/adapters/secondary"
 	"github.com/ab-dauletkhan/coc/internal/application/usecases"
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
+	"github.com/ab-dauletkhan/coc/internal/domain/ports"
 )
 
 func main() {
 	_ = godotenv.Load()
 
+	// Logging is set up before the configuration is loaded so that its warnings are
+	// structured too.
+	logger, err := logging.New(os.Stderr, os.Getenv("LOG_LEVEL"), os.Getenv("LOG_FORMAT"))
+	slog.SetDefault(logger)
+	if err != nil {
+		slog.Warn(err.Error())
+	}
+
 	cfg := config.Load()
 
 	// Try to detect public outbound IP for allowlisting
 	if ip := fetchPublicIP(); ip != "" {
-		log.Printf("public outbound IP (for allowlist): %s", ip)
+		slog.Info("public outbound IP (for allowlist)", "ip", ip)
 	} else {
-		log.Println("public outbound IP not detected (network may block metadata services)")
+		slog.Info("public outbound IP not detected (network may block metadata services)")
 	}
 
-	r := gin.Default()
-	_ = r.SetTrustedProxies(nil)
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.TracesExporter, cfg.ServiceName)
+	if err != nil {
+		fatal("failed to set up tracing", "error", err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Warn("failed to flush traces", "error", err)
+		}
+	}()
 
-	// Health check
-	r.GET("/healthz", func(c *gin.Context) { c.JSON(200, gin.H{"status": "ok"}) })
+	// gin's debug output (route table, mode warnings) goes through the logger as well.
+	gin.DebugPrintRouteFunc = func(method, path, handler string, _ int) {
+		slog.Debug("route registered", "method", method, "path", path, "handler", handler)
+	}
+	gin.DebugPrintFunc = func(format string, values ...any) {
+		slog.Debug(strings.TrimSpace(fmt.Sprintf(format, values...)))
+	}
 
-	cocClient := coc.NewClient(cfg.CocBaseURL, cfg.CocAPIToken)
+	r := gin.New()
+	// Client IPs come from X-Forwarded-For/X-Real-IP only behind a trusted proxy.
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		fatal("invalid TRUSTED_PROXIES", "error", err)
+	}
+	r.Use(
+		primaryhttp.RequestID(),
+		primaryhttp.Tracing(cfg.ServiceName),
+		primaryhttp.AccessLog(),
+		primaryhttp.Metrics(),
+		primaryhttp.Recovery(),
+		// Limited per IP before authentication, so floods without a key are cut off too.
+		primaryhttp.InboundRateLimit(ratelimit.NewKeyed(cfg.InboundRateLimit, cfg.InboundRateBurst), cfg.InboundRouteWeights),
+		primaryhttp.CacheHints(),
+	)
+	if cfg.CompressionEnabled {
+		r.Use(primaryhttp.Compression(cfg.CompressionMinSize))
+	}
+	// After compression, so it sees the handlers' uncompressed output.
+	if cfg.OpenAPIValidate {
+		spec, err := primaryhttp.LoadOpenAPISpec()
+		if err != nil {
+			fatal("failed to load OpenAPI spec", "error", err)
+		}
+		r.Use(primaryhttp.OpenAPIValidation(spec))
+	}
+	primaryhttp.RegisterMetrics(r)
+
+	cocClient := coc.NewClient(cfg.CocBaseURL, cfg.CocAPIToken).
+		WithLimiter(coc.NewLimiter(cfg.CocRateLimit, cfg.CocRateBurst)).
+		WithBreaker(coc.NewBreaker(cfg.CocBreakerThreshold, cfg.CocBreakerCooldown))
 
 	catalogPath := getenv("EQUIPMENT_CATALOG_PATH", "data/hero_equipment.json")
 	cat, err := catalog.LoadEquipmentCatalog(catalogPath)
+	metrics.ObserveCatalogLoad("load", err)
+	if err != nil {
+		slog.Warn("failed to load catalog", "path", catalogPath, "error", err)
+	} else {
+		slog.Info("loaded catalog", "path", catalogPath, "items", len(cat.Items))
+	}
+
+	store, err := secondary.NewFileStore(cfg.StorePath)
 	if err != nil {
-		log.Printf("warning: failed to load catalog at %s: %v", catalogPath, err)
+		fatal("failed to open store", "path", cfg.StorePath, "error", err)
+	}
+
+	// With authentication on, every /v1 request needs an API key and counts against
+	// the key's rate limit and daily quota.
+	if cfg.AuthEnabled {
+		apiKeyUC := usecases.NewAPIKeyUseCase(store, usecases.APIKeySettings{
+			Static:     staticAPIKeys(cfg.APIKeys),
+			RateLimit:  cfg.APIRateLimit,
+			RateBurst:  cfg.APIRateBurst,
+			DailyQuota: cfg.APIDailyQuota,
+		})
+		r.Use(primaryhttp.APIKeyAuth(apiKeyUC))
+		apiKeyHandler := primaryhttp.NewAPIKeyHandler(apiKeyUC)
+		apiKeyHandler.Register(r)
 	}
 
 	// Handlers
 	// Hexagonal handlers
 	catalogAdapter := secondary.NewCatalogAdapter(cat)
+	go reloadCatalogOnHangup(catalogAdapter, catalogPath)
 	cocAdapter := secondary.NewCocAPIAdapter(cocClient)
-	playerCostsUC := usecases.NewPlayerEquipmentCostsUseCase(cocAdapter, catalogAdapter)
+
+	healthUC := usecases.NewHealthUseCase(cocAdapter, catalogAdapter, store, usecases.HealthSettings{
+		TokenConfigured: cfg.CocAPIToken != "",
+		ProbeAfter:      cfg.ReadyProbeAfter,
+	})
+	healthHandler := primaryhttp.NewHealthHandler(healthUC)
+	healthHandler.Register(r)
+
+	// Detected changes are delivered to webhook subscribers in the background.
+	webhookDispatcher := usecases.NewWebhookDispatcher(store, secondary.NewHTTPWebhookSender(cfg.WebhookTimeout, cfg.WebhookAllowPrivate), usecases.WebhookSettings{
+		MaxAttempts: cfg.WebhookMaxAttempts,
+		Backoff:     cfg.WebhookBackoff,
+		MaxBackoff:  cfg.WebhookMaxBackoff,
+		Concurrency: cfg.WebhookConcurrency,
+	})
+	// Background work stops on shutdown; workers lets main wait for it to wind down.
+	background, stopBackground := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	workers.Go(func() { webhookDispatcher.Run(background) })
+	// Live event streams subscribe to the broker.
+	eventBroker := usecases.NewEventBroker(64)
+	publisher := usecases.EventPublishers{webhookDispatcher, eventBroker}
+
+	// Player fetches go through the recorder so every payload we see adds to history.
+	var playerAPI ports.PlayerAPI = cocAdapter
+	if cfg.SnapshotsEnabled {
+		recorder := usecases.NewSnapshotRecorder(cocAdapter, store, publisher, usecases.SnapshotRetention{
+			MaxAge:       cfg.SnapshotRetention,
+			MaxPerPlayer: cfg.SnapshotMaxPerPlayer,
+		})
+		if n, err := recorder.Prune(context.Background()); err != nil {
+			slog.Warn("failed to prune snapshots", "error", err)
+		} else if n > 0 {
+			slog.Info("pruned snapshots past retention", "count", n)
+		}
+		playerAPI = recorder
+	}
+
+	// Every member fan-out shares one worker pool so concurrent requests cannot
+	// multiply the upstream load.
+	fanOut := usecases.NewFanOut(usecases.FanOutSettings{
+		Workers:       cfg.FanOutWorkers,
+		MemberTimeout: cfg.MemberFetchTimeout,
+		Metrics:       metrics.Recorder{},
+	})
+
+	playerCostsUC := usecases.NewPlayerEquipmentCostsUseCase(playerAPI, catalogAdapter)
 	playerCostsHandler := primaryhttp.NewPlayerEquipmentCostsHandler(playerCostsUC)
 	playerCostsHandler.Register(r)
 
-	playerEquipUC := usecases.NewPlayerHeroEquipmentsUseCase(cocAdapter, catalogAdapter)
+	playerEquipUC := usecases.NewPlayerHeroEquipmentsUseCase(playerAPI, catalogAdapter)
 	playerEquipHandler := primaryhttp.NewPlayerHeroEquipmentsHandler(playerEquipUC)
 	playerEquipHandler.Register(r)
 
-	clanCostsUC := usecases.NewClanEquipmentCostsUseCase(cocAdapter, cocAdapter, catalogAdapter)
-	clanCostsHandler := primaryhttp.NewClanEquipmentCostsHandler(clanCostsUC)
+	playerBatchUC := usecases.NewPlayerBatchUseCase(playerAPI, catalogAdapter, fanOut)
+	playerBatchHandler := primaryhttp.NewPlayerBatchHandler(playerBatchUC, cfg.BatchRequestTimeout)
+	playerBatchHandler.Register(r)
+
+	playerHistoryUC := usecases.NewPlayerEquipmentHistoryUseCase(store, catalogAdapter)
+	playerHistoryHandler := primaryhttp.NewPlayerEquipmentHistoryHandler(playerHistoryUC)
+	playerHistoryHandler.Register(r)
+
+	clanCostsUC := usecases.NewClanEquipmentCostsUseCase(cocAdapter, playerAPI, catalogAdapter, fanOut)
+	clanCostsHandler := primaryhttp.NewClanEquipmentCostsHandler(clanCostsUC, cfg.ClanRequestTimeout)
 	clanCostsHandler.Register(r)
 
+	clanLeaderboardUC := usecases.NewClanEquipmentLeaderboardUseCase(cocAdapter, playerAPI, catalogAdapter, fanOut)
+	clanLeaderboardHandler := primaryhttp.NewClanEquipmentLeaderboardHandler(clanLeaderboardUC, cfg.ClanRequestTimeout)
+	clanLeaderboardHandler.Register(r)
+
+	familyUC := usecases.NewClanFamilyUseCase(store, cocAdapter, playerAPI, catalogAdapter, fanOut, cfg.FamilyMaxClans)
+	familyHandler := primaryhttp.NewClanFamilyHandler(familyUC, cfg.FamilyRequestTimeout)
+	familyHandler.Register(r)
+	// Families are shared by every caller, so changing them needs a key with the
+	// write scope.
+	if cfg.AuthEnabled {
+		familyHandler.RegisterWrites(r)
+	}
+
+	// Linked accounts belong to the user of an API key, so they need authentication.
+	if cfg.AuthEnabled {
+		accountLinkUC := usecases.NewAccountLinkUseCase(store, cocAdapter, playerAPI, catalogAdapter, fanOut)
+		accountLinkHandler := primaryhttp.NewAccountLinkHandler(accountLinkUC, cfg.ClanRequestTimeout, cfg.MemberFetchTimeout)
+		accountLinkHandler.Register(r)
+	}
+
+	membershipUC := usecases.NewClanMembershipUseCase(store, publisher)
+	clanWatchUC := usecases.NewClanWatchUseCase(eventBroker, store, store)
+	clanEventsHandler := primaryhttp.NewClanEventsHandler(membershipUC, clanWatchUC)
+	clanEventsHandler.Register(r)
+
+	// Subscriptions receive every event and make the server send requests, so managing
+	// them needs an admin key.
+	if cfg.AuthEnabled {
+		webhookUC := usecases.NewWebhookUseCase(store, cfg.WebhookAllowPrivate)
+		webhookHandler := primaryhttp.NewWebhookHandler(webhookUC)
+		webhookHandler.Register(r)
+	}
+
+	tracker := usecases.NewTracker(store, cocAdapter, playerAPI, membershipUC, fanOut, usecases.TrackerSettings{
+		Tick:            cfg.TrackerTick,
+		Concurrency:     cfg.TrackerConcurrency,
+		DefaultInterval: cfg.TrackerDefaultInterval,
+		MinInterval:     cfg.TrackerMinInterval,
+	})
+	trackerHandler := primaryhttp.NewTrackerHandler(tracker)
+	trackerHandler.Register(r)
+	// Tracked entries make the server poll upstream on its own, so changing them
+	// needs a key with the write scope.
+	if cfg.AuthEnabled {
+		trackerHandler.RegisterWrites(r)
+	}
+	if cfg.TrackerEnabled {
+		workers.Go(func() { tracker.Run(background) })
+	}
+
 	// Swagger UI & spec
 	primaryhttp.RegisterSwagger(r)
 
-	if err := r.Run(cfg.ServerAddr); err != nil {
-		log.Fatalf("server failed: %v", err)
+	srv := &http.Server{
+		Addr:              cfg.ServerAddr,
+		Handler:           r,
+		ReadTimeout:       cfg.ServerReadTimeout,
+		ReadHeaderTimeout: cfg.ServerReadHeaderTimeout,
+		WriteTimeout:      cfg.ServerWriteTimeout,
+		IdleTimeout:       cfg.ServerIdleTimeout,
+		MaxHeaderBytes:    cfg.ServerMaxHeaderBytes,
+		ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
+	}
+	// Live event streams never end on their own; closing the broker ends them so
+	// Shutdown does not wait for them until the deadline.
+	srv.RegisterOnShutdown(eventBroker.Close)
+
+	signals, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- srv.ListenAndServe() }()
+	slog.Info("listening", "addr", cfg.ServerAddr)
+
+	select {
+	case err := <-serveErr:
+		fatal("server failed", "error", err)
+	case <-signals.Done():
+	}
+	// A second signal exits immediately.
+	stopSignals()
+	slog.Info("shutting down", "timeout", cfg.ShutdownTimeout.String())
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		slog.Warn("in-flight requests did not finish before the shutdown deadline", "error", err)
 	}
+	stopBackground()
+	drained := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		slog.Warn("background work did not finish before the shutdown deadline")
+	}
+	if err := store.Close(); err != nil {
+		slog.Error("failed to flush store", "path", cfg.StorePath, "error", err)
+	}
+	slog.Info("stopped")
+}
+
+// reloadCatalogOnHangup reloads the equipment catalog from path on every SIGHUP. A
+// catalog that fails to load leaves the current one in place.
+func reloadCatalogOnHangup(adapter *secondary.CatalogAdapter, path string) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	for range hup {
+		cat, err := catalog.LoadEquipmentCatalog(path)
+		metrics.ObserveCatalogLoad("reload", err)
+		if err != nil {
+			slog.Warn("failed to reload catalog", "path", path, "error", err)
+			continue
+		}
+		adapter.Replace(cat)
+		slog.Info("reloaded catalog", "path", path, "items", len(cat.Items))
+	}
+}
+
+// staticAPIKeys converts the keys defined in the configuration, dropping unknown
+// scopes. A key's ID is its name.
+func staticAPIKeys(keys []config.APIKey) []models.APIKey {
+	out := make([]models.APIKey, 0, len(keys))
+	for _, k := range keys {
+		key := models.APIKey{
+			ID:         k.Name,
+			Name:       k.Name,
+			SecretHash: usecases.HashAPIKeySecret(k.Secret),
+			UserID:     k.UserID,
+			Static:     true,
+		}
+		for _, s := range k.Scopes {
+			if !slices.Contains(models.Scopes, s) {
+				slog.Warn("unknown API key scope; ignoring", "key", k.Name, "scope", s)
+				continue
+			}
+			key.Scopes = append(key.Scopes, s)
+		}
+		out = append(out, key)
+	}
+	return out
+}
+
+// fatal logs msg at error level and exits.
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
 }
 
 func getenv(key, def string) string {
@@ -0,0 +1,103 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ab-dauletkhan/coc/internal/application/usecases"
+)
+
+type AccountLinkHandler struct {
+	uc *usecases.AccountLinkUseCase
+	// listTimeout covers fetching every linked account, like a clan's members;
+	// linkTimeout covers verifying a token and fetching the one player.
+	listTimeout time.Duration
+	linkTimeout time.Duration
+}
+
+func NewAccountLinkHandler(uc *usecases.AccountLinkUseCase, listTimeout, linkTimeout time.Duration) *AccountLinkHandler {
+	return &AccountLinkHandler{uc: uc, listTimeout: listTimeout, linkTimeout: linkTimeout}
+}
+
+// Register adds the /v1/me/accounts routes, which act for the user of the request's
+// API key. Register them only when APIKeyAuth is in use.
+func (h *AccountLinkHandler) Register(r *gin.Engine) {
+	r.GET("/v1/me/accounts", h.list)
+	r.POST("/v1/me/accounts", h.link)
+	r.DELETE("/v1/me/accounts/:tag", h.unlink)
+}
+
+// currentUserID returns the user of the request's API key, or "" without one. The
+// caller is never taken from a client-supplied header.
+func currentUserID(c *gin.Context) string {
+	if key, ok := currentAPIKey(c); ok {
+		return key.UserID
+	}
+	return ""
+}
+
+func (h *AccountLinkHandler) list(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing or invalid API key"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.listTimeout)
+	defer cancel()
+
+	res, status, err := h.uc.Accounts(ctx, userID)
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	respondJSON(c, http.StatusOK, res)
+}
+
+func (h *AccountLinkHandler) link(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing or invalid API key"})
+		return
+	}
+	var body struct {
+		PlayerTag string `json:"playerTag"`
+		Token     string `json:"token"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.linkTimeout)
+	defer cancel()
+
+	res, status, err := h.uc.Link(ctx, userID, body.PlayerTag, body.Token)
+	if err != nil {
+		if status == 0 {
+			status = http.StatusBadGateway
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	if status >= 400 {
+		c.Status(status)
+		return
+	}
+	respondJSON(c, http.StatusCreated, res)
+}
+
+func (h *AccountLinkHandler) unlink(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing or invalid API key"})
+		return
+	}
+	status, err := h.uc.Unlink(c.Request.Context(), userID, c.Param("tag"))
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,201 @@
+package http
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ab-dauletkhan/coc/internal/application/usecases"
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
+	"github.com/ab-dauletkhan/coc/internal/logging"
+	"github.com/ab-dauletkhan/coc/internal/metrics"
+)
+
+// apiKeyHeader is an alternative to "Authorization: Bearer <key>".
+const apiKeyHeader = "X-API-Key"
+
+// apiKeyContextKey stores the authenticated models.APIKey in the gin context.
+const apiKeyContextKey = "apiKey"
+
+// APIKeyAuth requires a valid API key on /v1 requests, with the read scope for GET
+// and HEAD and the write scope otherwise, and enforces the key's rate limit and daily
+// quota. Other paths (health, metrics, docs) are left open.
+func APIKeyAuth(uc *usecases.APIKeyUseCase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !strings.HasPrefix(c.Request.URL.Path, "/v1/") {
+			c.Next()
+			return
+		}
+		addVary(c, "Authorization", apiKeyHeader)
+		key, ok, err := uc.Authenticate(c.Request.Context(), presentedAPIKey(c))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if !ok {
+			metrics.ObserveAPIKeyRequest("", "unauthorized")
+			c.Header("WWW-Authenticate", `Bearer realm="coc"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing or invalid API key"})
+			return
+		}
+		scope := models.ScopeWrite
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			scope = models.ScopeRead
+		}
+		if !key.Allows(scope) {
+			metrics.ObserveAPIKeyRequest(key.Name, "forbidden")
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API key lacks the " + scope + " scope"})
+			return
+		}
+
+		adm, status, err := uc.Admit(c.Request.Context(), key)
+		if adm.Quota > 0 {
+			c.Header("X-Quota-Limit", strconv.Itoa(adm.Quota))
+			c.Header("X-Quota-Remaining", strconv.Itoa(adm.Remaining))
+		}
+		if err != nil {
+			if status == http.StatusTooManyRequests {
+				result := "rate_limited"
+				if errors.Is(err, usecases.ErrQuotaExceeded) {
+					result = "quota_exceeded"
+				}
+				metrics.ObserveAPIKeyRequest(key.Name, result)
+				c.Header("Retry-After", strconv.Itoa(retryAfterSeconds(adm.RetryAfter)))
+			}
+			c.AbortWithStatusJSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		metrics.ObserveAPIKeyRequest(key.Name, "ok")
+		c.Set(apiKeyContextKey, key)
+		logger := logging.FromContext(c.Request.Context()).With("api_key", key.Name)
+		c.Request = c.Request.WithContext(logging.WithLogger(c.Request.Context(), logger))
+		c.Next()
+	}
+}
+
+// RequireScope rejects requests whose API key lacks scope. It is meant for routes
+// registered only when authentication is enabled.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key, ok := currentAPIKey(c)
+		if !ok || !key.Allows(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API key lacks the " + scope + " scope"})
+			return
+		}
+		c.Next()
+	}
+}
+
+func presentedAPIKey(c *gin.Context) string {
+	if v := c.GetHeader("Authorization"); len(v) > 7 && strings.EqualFold(v[:7], "Bearer ") {
+		return strings.TrimSpace(v[7:])
+	}
+	return strings.TrimSpace(c.GetHeader(apiKeyHeader))
+}
+
+func currentAPIKey(c *gin.Context) (models.APIKey, bool) {
+	v, ok := c.Get(apiKeyContextKey)
+	if !ok {
+		return models.APIKey{}, false
+	}
+	key, ok := v.(models.APIKey)
+	return key, ok
+}
+
+// retryAfterSeconds rounds d up to whole seconds, as Retry-After requires.
+func retryAfterSeconds(d time.Duration) int {
+	return max(int(math.Ceil(d.Seconds())), 1)
+}
+
+type APIKeyHandler struct {
+	uc *usecases.APIKeyUseCase
+}
+
+func NewAPIKeyHandler(uc *usecases.APIKeyUseCase) *APIKeyHandler {
+	return &APIKeyHandler{uc: uc}
+}
+
+// Register adds the key management routes, which need the admin scope, and
+// /v1/me/usage for the calling key. Register them only when APIKeyAuth is in use.
+func (h *APIKeyHandler) Register(r *gin.Engine) {
+	admin := r.Group("/v1/api-keys", RequireScope(models.ScopeAdmin))
+	admin.GET("", h.list)
+	admin.POST("", h.create)
+	admin.DELETE("/:id", h.delete)
+	admin.GET("/:id/usage", h.usage)
+	r.GET("/v1/me/usage", h.me)
+}
+
+func (h *APIKeyHandler) list(c *gin.Context) {
+	res, status, err := h.uc.List(c.Request.Context())
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	respondJSON(c, http.StatusOK, gin.H{"apiKeys": res})
+}
+
+func (h *APIKeyHandler) create(c *gin.Context) {
+	var body struct {
+		Name       string   `json:"name"`
+		Scopes     []string `json:"scopes"`
+		UserID     string   `json:"userId"`
+		RateLimit  float64  `json:"rateLimit"`
+		RateBurst  int      `json:"rateBurst"`
+		DailyQuota int      `json:"dailyQuota"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	res, status, err := h.uc.Create(c.Request.Context(), usecases.CreateAPIKeyInput{
+		Name:       body.Name,
+		Scopes:     body.Scopes,
+		UserID:     body.UserID,
+		RateLimit:  body.RateLimit,
+		RateBurst:  body.RateBurst,
+		DailyQuota: body.DailyQuota,
+	})
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	respondJSON(c, http.StatusCreated, res)
+}
+
+func (h *APIKeyHandler) delete(c *gin.Context) {
+	status, err := h.uc.Delete(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *APIKeyHandler) usage(c *gin.Context) {
+	res, status, err := h.uc.Usage(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	respondJSON(c, http.StatusOK, res)
+}
+
+func (h *APIKeyHandler) me(c *gin.Context) {
+	key, ok := currentAPIKey(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing or invalid API key"})
+		return
+	}
+	res, status, err := h.uc.Usage(c.Request.Context(), key.ID)
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	respondJSON(c, http.StatusOK, res)
+}
@@ -0,0 +1,24 @@
+package http
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryAfterSeconds(t *testing.T) {
+	tests := []struct {
+		in   time.Duration
+		want int
+	}{
+		{0, 1},
+		{time.Millisecond, 1},
+		{time.Second, 1},
+		{time.Second + time.Nanosecond, 2},
+		{12 * time.Hour, 43200},
+	}
+	for _, tt := range tests {
+		if got := retryAfterSeconds(tt.in); got != tt.want {
+			t.Errorf("retryAfterSeconds(%s) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ab-dauletkhan/coc/internal/cachehint"
+)
+
+const jsonContentType = "application/json; charset=utf-8"
+
+// cacheHintsContextKey stores the request's *cachehint.Collector in the gin context.
+const cacheHintsContextKey = "cacheHints"
+
+// CacheHints collects the cache lifetime of the upstream responses a GET request
+// uses, for respondJSON to derive Cache-Control and Last-Modified from.
+func CacheHints() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+		ctx, hints := cachehint.WithCollector(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+		c.Set(cacheHintsContextKey, hints)
+		c.Next()
+	}
+}
+
+// respondJSON writes a successful result as JSON, keeping only the members selected
+// by the fields query parameter (see parseFields); a malformed selection is a 400.
+//
+// A 200 response to a GET request also gets a strong ETag computed from the body
+// and, when built from upstream data, Last-Modified and a max-age no longer than the
+// shortest upstream cache lifetime; a matching If-None-Match (or, without it,
+// If-Modified-Since) is answered with 304. Responses built from user-specific data or
+// for an API key are private, everything else public; negotiated and authenticated
+// routes also list Accept and the key headers in Vary. Other GET responses must be
+// revalidated.
+func respondJSON(c *gin.Context, status int, res any) {
+	fields, err := parseFields(c.Query("fields"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	body, err := json.Marshal(res)
+	if err == nil && fields != nil {
+		body, err = fields.filter(body)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if c.Request.Method != http.MethodGet {
+		c.Data(status, jsonContentType, body)
+		return
+	}
+	if status != http.StatusOK {
+		c.Header("Cache-Control", "no-cache")
+		c.Data(status, jsonContentType, body)
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	c.Header("ETag", etag)
+	var lastModified time.Time
+	cacheControl := "no-cache"
+	if hints, ok := c.Get(cacheHintsContextKey); ok {
+		h := hints.(*cachehint.Collector)
+		if maxAge, ok := h.MaxAge(time.Now()); ok {
+			cacheControl = "max-age=" + strconv.Itoa(int(maxAge/time.Second))
+		}
+		if lastModified = h.LastModified(); !lastModified.IsZero() {
+			c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+		}
+	}
+	if _, ok := currentAPIKey(c); ok || strings.HasPrefix(c.FullPath(), "/v1/me/") {
+		cacheControl = "private, " + cacheControl
+	} else if cacheControl != "no-cache" {
+		cacheControl = "public, " + cacheControl
+	}
+	c.Header("Cache-Control", cacheControl)
+
+	if notModified(c.Request, etag, lastModified) {
+		c.Status(http.StatusNotModified)
+		c.Writer.WriteHeaderNow()
+		return
+	}
+	c.Data(http.StatusOK, jsonContentType, body)
+}
+
+// notModified evaluates If-None-Match, or If-Modified-Since when it is absent
+// (RFC 9110, section 13.2.2).
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, tag := range strings.Split(inm, ",") {
+			tag = trimEncodingSuffix(strings.TrimPrefix(strings.TrimSpace(tag), "W/"))
+			if tag == "*" || tag == etag {
+				return true
+			}
+		}
+		return false
+	}
+	if lastModified.IsZero() {
+		return false
+	}
+	ims, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	return err == nil && !lastModified.Truncate(time.Second).After(ims)
+}
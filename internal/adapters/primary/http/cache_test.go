@@ -0,0 +1,85 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNotModified(t *testing.T) {
+	const etag = `"abc"`
+	modified := time.Date(2024, 3, 1, 12, 0, 30, 500e6, time.UTC)
+	tests := []struct {
+		name         string
+		header       map[string]string
+		lastModified time.Time
+		want         bool
+	}{
+		{"no validators", nil, modified, false},
+		{"matching tag", map[string]string{"If-None-Match": `"abc"`}, modified, true},
+		{"other tag", map[string]string{"If-None-Match": `"xyz"`}, modified, false},
+		{"tag in a list", map[string]string{"If-None-Match": `"xyz", "abc"`}, modified, true},
+		{"weak comparison", map[string]string{"If-None-Match": `W/"abc"`}, modified, true},
+		{"any tag", map[string]string{"If-None-Match": `*`}, time.Time{}, true},
+		{"unquoted tag", map[string]string{"If-None-Match": `abc`}, modified, false},
+		{"brotli tag", map[string]string{"If-None-Match": `"abc-br"`}, modified, true},
+		{"gzip tag", map[string]string{"If-None-Match": `"abc-gzip"`}, modified, true},
+		{"weak gzip tag in a list", map[string]string{"If-None-Match": `"xyz-br", W/"abc-gzip"`}, modified, true},
+		{"other coding", map[string]string{"If-None-Match": `"abc-deflate"`}, modified, false},
+		{"suffix of another tag", map[string]string{"If-None-Match": `"xyz-br"`}, modified, false},
+		{"if-none-match wins over a matching date", map[string]string{
+			"If-None-Match":     `"xyz"`,
+			"If-Modified-Since": modified.Add(time.Hour).Format(http.TimeFormat),
+		}, modified, false},
+		{"modified since", map[string]string{"If-Modified-Since": modified.Add(-time.Second).Format(http.TimeFormat)}, modified, false},
+		{"same second", map[string]string{"If-Modified-Since": modified.Format(http.TimeFormat)}, modified, true},
+		{"later date", map[string]string{"If-Modified-Since": modified.Add(time.Hour).Format(http.TimeFormat)}, modified, true},
+		{"date without last-modified", map[string]string{"If-Modified-Since": modified.Format(http.TimeFormat)}, time.Time{}, false},
+		{"malformed date", map[string]string{"If-Modified-Since": "yesterday"}, modified, false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		for k, v := range tt.header {
+			r.Header.Set(k, v)
+		}
+		if got := notModified(r, etag, tt.lastModified); got != tt.want {
+			t.Errorf("%s: notModified = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNegotiatedResponsesVary(t *testing.T) {
+	r, _ := newTestRouter(t)
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"/v1/players/%23P1/hero-equipments", []string{"Accept", "Authorization", "X-API-Key"}},
+		{"/v1/players/%23P1/hero-equipments/costs", []string{"Accept", "Authorization", "X-API-Key"}},
+		{"/v1/clans/%23C1/hero-equipments/costs", []string{"Accept", "Authorization", "X-API-Key"}},
+		{"/v1/families", []string{"Authorization", "X-API-Key"}},
+	}
+	for _, tt := range tests {
+		w := serve(r, "GET", tt.path, "")
+		var got []string
+		for _, v := range w.Header().Values("Vary") {
+			for _, f := range strings.Split(v, ",") {
+				got = append(got, strings.TrimSpace(f))
+			}
+		}
+		for _, h := range tt.want {
+			n := 0
+			for _, g := range got {
+				if g == h {
+					n++
+				}
+			}
+			if n != 1 {
+				t.Errorf("GET %s: Vary %q lists %s %d times, want once", tt.path, got, h, n)
+			}
+		}
+	}
+}
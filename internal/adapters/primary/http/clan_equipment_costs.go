@@ -2,7 +2,11 @@ package http
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -12,17 +16,30 @@ import (
 
 type ClanEquipmentCostsHandler struct {
 	uc *usecases.ClanEquipmentCostsUseCase
+	// timeout is the deadline of a buffered (non-streamed) response.
+	timeout time.Duration
 }
 
-func NewClanEquipmentCostsHandler(uc *usecases.ClanEquipmentCostsUseCase) *ClanEquipmentCostsHandler {
-	return &ClanEquipmentCostsHandler{uc: uc}
+func NewClanEquipmentCostsHandler(uc *usecases.ClanEquipmentCostsUseCase, timeout time.Duration) *ClanEquipmentCostsHandler {
+	return &ClanEquipmentCostsHandler{uc: uc, timeout: timeout}
 }
 
 func (h *ClanEquipmentCostsHandler) Register(r *gin.Engine) {
 	r.GET("/v1/clans/:tag/hero-equipments/costs", h.get)
+	r.GET("/v1/clans/:tag/hero-equipments/costs/stream", h.stream)
 }
 
+// clanStreamTimeout bounds a streamed computation; members are still bounded by their
+// own fetch timeout, so this only guards against very large or stuck fan-outs.
+const clanStreamTimeout = time.Minute
+
 func (h *ClanEquipmentCostsHandler) get(c *gin.Context) {
+	// Streamed results are flushed as they arrive under the longer clanStreamTimeout,
+	// so slow members no longer cost the whole response.
+	if acceptsNDJSON(c) {
+		h.streamNDJSON(c)
+		return
+	}
 	tag := c.Param("tag")
 	if tag == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "missing tag"})
@@ -30,10 +47,51 @@ func (h *ClanEquipmentCostsHandler) get(c *gin.Context) {
 	}
 	nTag := normalizePlayerTag(tag)
 
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	q, err := parseClanCostsQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	format, err := reportFormat(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if format != formatJSON {
+		h.export(c, nTag, q, format)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.timeout)
+	defer cancel()
+
+	res, status, err := h.uc.Execute(ctx, nTag, q)
+	if err != nil {
+		if status == 0 {
+			status = http.StatusBadGateway
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	if status >= 400 {
+		c.Status(status)
+		return
+	}
+	// Callers opt into 206 so existing clients keep receiving 200 for partial results.
+	if !res.Complete && c.Query("partialContent") == "true" {
+		respondJSON(c, http.StatusPartialContent, res)
+		return
+	}
+	respondJSON(c, http.StatusOK, res)
+}
+
+// export sends one row per member and equipment; members that could not be fetched get
+// a single row with their status and no equipment.
+func (h *ClanEquipmentCostsHandler) export(c *gin.Context, nTag string, q usecases.ClanCostsQuery, format string) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.timeout)
 	defer cancel()
 
-	res, status, err := h.uc.Execute(ctx, nTag)
+	res, status, err := h.uc.Report(ctx, nTag, q)
 	if err != nil {
 		if status == 0 {
 			status = http.StatusBadGateway
@@ -45,5 +103,132 @@ func (h *ClanEquipmentCostsHandler) get(c *gin.Context) {
 		c.Status(status)
 		return
 	}
-	c.JSON(http.StatusOK, res)
+	clanTag := "#" + strings.TrimPrefix(res.ClanTag, "%23")
+	var rows [][]any
+	for _, m := range res.Members {
+		member := []any{clanTag, m.Tag, m.Name, m.Role, m.TownHall, string(m.Status)}
+		if len(m.Equipments) == 0 {
+			rows = append(rows, append(member, nil, nil, nil, nil, nil, nil, nil))
+			continue
+		}
+		for _, e := range m.Equipments {
+			rows = append(rows, append(slices.Clip(member), e.ID, e.Name, e.Rarity, e.Level, e.Spent.Shiny, e.Spent.Glowy, e.Spent.Starry))
+		}
+	}
+	writeTable(c, format, exportName("clan-equipment-costs", nTag), clanCostsHeader, rows)
+}
+
+var clanCostsHeader = []string{
+	"clanTag", "memberTag", "memberName", "role", "townHall", "status",
+	"equipmentId", "equipment", "rarity", "level", "shiny", "glowy", "starry",
+}
+
+// stream sends each member's result as a "member" event as soon as it is computed,
+// followed by a "summary" event with the clan totals.
+func (h *ClanEquipmentCostsHandler) stream(c *gin.Context) {
+	h.streamCosts(c,
+		func() { beginSSE(c) },
+		func(m usecases.ClanMemberSpend) { sendSSE(c, "member", m) },
+		func(sum usecases.ClanCostsSummary) { sendSSE(c, "summary", sum) },
+	)
+}
+
+// ndjsonMember and ndjsonSummary are the lines of an NDJSON costs response.
+type ndjsonMember struct {
+	Type string `json:"type"`
+	usecases.ClanMemberSpend
+}
+
+type ndjsonSummary struct {
+	Type string `json:"type"`
+	usecases.ClanCostsSummary
+}
+
+// streamNDJSON writes one {"type":"member"} line per member as soon as it is computed
+// and a final {"type":"summary"} line with the clan totals.
+func (h *ClanEquipmentCostsHandler) streamNDJSON(c *gin.Context) {
+	enc := json.NewEncoder(c.Writer)
+	writeLine := func(v any) {
+		_ = enc.Encode(v)
+		c.Writer.Flush()
+	}
+	h.streamCosts(c,
+		func() {
+			clearWriteDeadline(c)
+			c.Header("Content-Type", ndjsonContentType)
+			c.Header("X-Accel-Buffering", "no")
+			c.Status(http.StatusOK)
+		},
+		func(m usecases.ClanMemberSpend) { writeLine(ndjsonMember{Type: "member", ClanMemberSpend: m}) },
+		func(sum usecases.ClanCostsSummary) { writeLine(ndjsonSummary{Type: "summary", ClanCostsSummary: sum}) },
+	)
+}
+
+// streamCosts runs a streamed clan costs computation. begin is called once before the
+// first member or the summary is written.
+func (h *ClanEquipmentCostsHandler) streamCosts(c *gin.Context, begin func(), member func(usecases.ClanMemberSpend), summary func(usecases.ClanCostsSummary)) {
+	tag := c.Param("tag")
+	if tag == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing tag"})
+		return
+	}
+	q, err := parseClanCostsQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), clanStreamTimeout)
+	defer cancel()
+
+	started := false
+	emit := func(m usecases.ClanMemberSpend) {
+		if !started {
+			begin()
+			started = true
+		}
+		member(m)
+	}
+	// Errors are only returned before any member was emitted, so a plain response still fits.
+	sum, status, err := h.uc.Stream(ctx, normalizePlayerTag(tag), q, emit)
+	if err != nil {
+		if status == 0 {
+			status = http.StatusBadGateway
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	if status >= 400 {
+		c.Status(status)
+		return
+	}
+	if !started {
+		begin()
+	}
+	summary(sum)
+}
+
+func parseClanCostsQuery(c *gin.Context) (usecases.ClanCostsQuery, error) {
+	q := usecases.ClanCostsQuery{
+		Sort:   c.Query("sort"),
+		Order:  c.Query("order"),
+		Cursor: c.Query("cursor"),
+	}
+	for _, role := range strings.Split(c.Query("role"), ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			q.Roles = append(q.Roles, role)
+		}
+	}
+	var err error
+	if v := c.Query("minTownHall"); v != "" {
+		if q.MinTownHall, err = strconv.Atoi(v); err != nil {
+			return q, errInvalidParam("minTownHall")
+		}
+	}
+	if v := c.Query("limit"); v != "" {
+		if q.Limit, err = strconv.Atoi(v); err != nil {
+			return q, errInvalidParam("limit")
+		}
+	}
+	return q, nil
 }
@@ -0,0 +1,51 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/ab-dauletkhan/coc/internal/application/usecases"
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
+)
+
+func TestClanCostsReportFailedMembers(t *testing.T) {
+	r, _ := newTestRouter(t)
+
+	w := serve(r, "GET", "/v1/clans/%23C1/hero-equipments/costs", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("clan costs: status %d, want 200: %s", w.Code, w.Body)
+	}
+	var res usecases.ClanEquipmentCostsResult
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Complete || res.FailedMembers != 1 || res.Failures[usecases.MemberStatusNotFound] != 1 {
+		t.Errorf("complete %v failed %d failures %v, want #P404 not found", res.Complete, res.FailedMembers, res.Failures)
+	}
+	// #P1 and #P2 have the same equipment, so the total is twice each one's spend.
+	var want models.OreTotals
+	for _, m := range res.Members {
+		switch m.Tag {
+		case "#P404":
+			if m.Status != usecases.MemberStatusNotFound || m.Spent != (models.OreTotals{}) {
+				t.Errorf("#P404 = %+v, want not_found with nothing spent", m)
+			}
+		default:
+			if m.Status != usecases.MemberStatusOK {
+				t.Errorf("%s status %s, want ok", m.Tag, m.Status)
+			}
+			want.Add(m.Spent)
+		}
+	}
+	if len(res.Members) != 3 || res.Total != want || want == (models.OreTotals{}) {
+		t.Errorf("members %+v total %+v, want 3 members and the total of #P1 and #P2", res.Members, res.Total)
+	}
+
+	if w := serve(r, "GET", "/v1/clans/%23C1/hero-equipments/costs?partialContent=true", ""); w.Code != http.StatusPartialContent {
+		t.Errorf("partialContent=true: status %d, want 206", w.Code)
+	}
+	if w := serve(r, "GET", "/v1/clans/%23C1/hero-equipments/costs?partialContent=true&role=leader,member", ""); w.Code != http.StatusOK {
+		t.Errorf("partialContent=true without failed members: status %d, want 200: %s", w.Code, w.Body)
+	}
+}
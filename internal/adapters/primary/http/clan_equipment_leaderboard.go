@@ -0,0 +1,82 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ab-dauletkhan/coc/internal/application/usecases"
+)
+
+type ClanEquipmentLeaderboardHandler struct {
+	uc      *usecases.ClanEquipmentLeaderboardUseCase
+	timeout time.Duration
+}
+
+func NewClanEquipmentLeaderboardHandler(uc *usecases.ClanEquipmentLeaderboardUseCase, timeout time.Duration) *ClanEquipmentLeaderboardHandler {
+	return &ClanEquipmentLeaderboardHandler{uc: uc, timeout: timeout}
+}
+
+func (h *ClanEquipmentLeaderboardHandler) Register(r *gin.Engine) {
+	r.GET("/v1/clans/:tag/hero-equipments/leaderboard", h.leaderboard)
+	r.GET("/v1/clans/:tag/hero-equipments/matrix", h.matrix)
+}
+
+func (h *ClanEquipmentLeaderboardHandler) leaderboard(c *gin.Context) {
+	tag := c.Param("tag")
+	if tag == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing tag"})
+		return
+	}
+	equipment := c.Query("equipment")
+	if equipment == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing equipment"})
+		return
+	}
+	nTag := normalizePlayerTag(tag)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.timeout)
+	defer cancel()
+
+	res, status, err := h.uc.Execute(ctx, nTag, equipment)
+	if err != nil {
+		if status == 0 {
+			status = http.StatusBadGateway
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	if status >= 400 {
+		c.Status(status)
+		return
+	}
+	respondJSON(c, http.StatusOK, res)
+}
+
+func (h *ClanEquipmentLeaderboardHandler) matrix(c *gin.Context) {
+	tag := c.Param("tag")
+	if tag == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing tag"})
+		return
+	}
+	nTag := normalizePlayerTag(tag)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.timeout)
+	defer cancel()
+
+	res, status, err := h.uc.Matrix(ctx, nTag)
+	if err != nil {
+		if status == 0 {
+			status = http.StatusBadGateway
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	if status >= 400 {
+		c.Status(status)
+		return
+	}
+	respondJSON(c, http.StatusOK, res)
+}
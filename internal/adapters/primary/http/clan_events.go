@@ -0,0 +1,80 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ab-dauletkhan/coc/internal/application/usecases"
+)
+
+type ClanEventsHandler struct {
+	uc    *usecases.ClanMembershipUseCase
+	watch *usecases.ClanWatchUseCase
+}
+
+func NewClanEventsHandler(uc *usecases.ClanMembershipUseCase, watch *usecases.ClanWatchUseCase) *ClanEventsHandler {
+	return &ClanEventsHandler{uc: uc, watch: watch}
+}
+
+func (h *ClanEventsHandler) Register(r *gin.Engine) {
+	r.GET("/v1/clans/:tag/events", h.get)
+	r.GET("/v1/clans/:tag/events/stream", h.stream)
+}
+
+func (h *ClanEventsHandler) get(c *gin.Context) {
+	tag := c.Param("tag")
+	if tag == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing tag"})
+		return
+	}
+	from, err := parseTimeParam(c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errInvalidParam("from").Error()})
+		return
+	}
+	to, err := parseTimeParam(c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errInvalidParam("to").Error()})
+		return
+	}
+	var types []string
+	for _, t := range strings.Split(c.Query("type"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			types = append(types, t)
+		}
+	}
+
+	res, status, err := h.uc.Events(c.Request.Context(), tag, from, to, types)
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	respondJSON(c, http.StatusOK, res)
+}
+
+// stream sends the clan's events as the tracker detects them, named by event type,
+// until the client disconnects.
+func (h *ClanEventsHandler) stream(c *gin.Context) {
+	events, status, err := h.watch.Watch(c.Request.Context(), c.Param("tag"))
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	beginSSE(c)
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			sendSSE(c, string(e.Type), e)
+		case <-heartbeat.C:
+			sendSSEHeartbeat(c)
+		}
+	}
+}
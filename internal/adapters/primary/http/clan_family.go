@@ -0,0 +1,144 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ab-dauletkhan/coc/internal/application/usecases"
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
+)
+
+type ClanFamilyHandler struct {
+	uc *usecases.ClanFamilyUseCase
+	// timeout covers fetching several clans' members; it is normally larger than the
+	// single-clan timeout because the player fan-out grows with the family.
+	timeout time.Duration
+}
+
+func NewClanFamilyHandler(uc *usecases.ClanFamilyUseCase, timeout time.Duration) *ClanFamilyHandler {
+	return &ClanFamilyHandler{uc: uc, timeout: timeout}
+}
+
+func (h *ClanFamilyHandler) Register(r *gin.Engine) {
+	r.GET("/v1/families", h.list)
+	r.GET("/v1/families/:name", h.get)
+	r.GET("/v1/families/:name/hero-equipments/costs", h.costs)
+	r.GET("/v1/families/:name/hero-equipments/leaderboard", h.leaderboard)
+	r.GET("/v1/families/:name/hero-equipments/matrix", h.matrix)
+}
+
+// RegisterWrites adds the routes that save and delete families, which need the write
+// scope. Register them only when APIKeyAuth is in use.
+func (h *ClanFamilyHandler) RegisterWrites(r *gin.Engine) {
+	g := r.Group("/v1/families", RequireScope(models.ScopeWrite))
+	g.PUT("/:name", h.put)
+	g.DELETE("/:name", h.delete)
+}
+
+func (h *ClanFamilyHandler) list(c *gin.Context) {
+	res, status, err := h.uc.List(c.Request.Context())
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	respondJSON(c, http.StatusOK, gin.H{"items": res})
+}
+
+func (h *ClanFamilyHandler) get(c *gin.Context) {
+	res, status, err := h.uc.Get(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	respondJSON(c, http.StatusOK, res)
+}
+
+func (h *ClanFamilyHandler) put(c *gin.Context) {
+	var body struct {
+		ClanTags []string `json:"clanTags"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	res, status, err := h.uc.Save(c.Request.Context(), c.Param("name"), body.ClanTags)
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	respondJSON(c, http.StatusOK, res)
+}
+
+func (h *ClanFamilyHandler) delete(c *gin.Context) {
+	status, err := h.uc.Delete(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *ClanFamilyHandler) costs(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.timeout)
+	defer cancel()
+
+	res, status, err := h.uc.Costs(ctx, c.Param("name"))
+	if err != nil {
+		if status == 0 {
+			status = http.StatusBadGateway
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	if status >= 400 {
+		c.Status(status)
+		return
+	}
+	respondJSON(c, http.StatusOK, res)
+}
+
+func (h *ClanFamilyHandler) leaderboard(c *gin.Context) {
+	equipment := c.Query("equipment")
+	if equipment == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing equipment"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.timeout)
+	defer cancel()
+
+	res, status, err := h.uc.Leaderboard(ctx, c.Param("name"), equipment)
+	if err != nil {
+		if status == 0 {
+			status = http.StatusBadGateway
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	if status >= 400 {
+		c.Status(status)
+		return
+	}
+	respondJSON(c, http.StatusOK, res)
+}
+
+func (h *ClanFamilyHandler) matrix(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.timeout)
+	defer cancel()
+
+	res, status, err := h.uc.Matrix(ctx, c.Param("name"))
+	if err != nil {
+		if status == 0 {
+			status = http.StatusBadGateway
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	if status >= 400 {
+		c.Status(status)
+		return
+	}
+	respondJSON(c, http.StatusOK, res)
+}
@@ -0,0 +1,181 @@
+package http
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+// Content codings negotiated by Compression, in order of preference.
+const (
+	encodingBrotli = "br"
+	encodingGzip   = "gzip"
+)
+
+// brotliLevel trades a little ratio for speed, as responses are compressed per request.
+const brotliLevel = 4
+
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(io.Writer)
+}
+
+var encoderPools = map[string]*sync.Pool{
+	encodingBrotli: {New: func() any { return brotli.NewWriterLevel(io.Discard, brotliLevel) }},
+	encodingGzip:   {New: func() any { return gzip.NewWriter(io.Discard) }},
+}
+
+// Compression encodes responses with brotli or gzip, whichever the client prefers in
+// Accept-Encoding (brotli on a tie). Only textual content types are compressed, and
+// only when the first write is at least minSize bytes; event and NDJSON streams,
+// bodiless responses, and responses that already set Content-Encoding are sent as
+// is. Strong ETags of compressed responses get the coding appended, since the bytes
+// differ from the identity response.
+func Compression(minSize int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == "" || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+		w := &compressWriter{ResponseWriter: c.Writer, encoding: encoding, minSize: minSize}
+		c.Writer = w
+		defer func() {
+			w.close()
+			c.Writer = w.ResponseWriter
+		}()
+		c.Next()
+	}
+}
+
+// negotiateEncoding returns the supported coding with the highest quality value in
+// an Accept-Encoding header, or "" when none is acceptable.
+func negotiateEncoding(header string) string {
+	q := map[string]float64{}
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		weight := 1.0
+		for _, param := range strings.Split(params, ";") {
+			key, v, _ := strings.Cut(param, "=")
+			if strings.EqualFold(strings.TrimSpace(key), "q") {
+				if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+					weight = f
+				}
+			}
+		}
+		switch name {
+		case encodingBrotli, encodingGzip:
+			q[name] = weight
+		case "*":
+			for _, e := range []string{encodingBrotli, encodingGzip} {
+				if _, ok := q[e]; !ok {
+					q[e] = weight
+				}
+			}
+		}
+	}
+	best, bestQ := "", 0.0
+	for _, e := range []string{encodingBrotli, encodingGzip} {
+		if q[e] > bestQ {
+			best, bestQ = e, q[e]
+		}
+	}
+	return best
+}
+
+func compressible(contentType string) bool {
+	ct, _, _ := strings.Cut(contentType, ";")
+	ct = strings.TrimSpace(ct)
+	switch {
+	case ct == "text/event-stream" || ct == ndjsonContentType:
+		return false
+	case strings.HasPrefix(ct, "text/"), ct == "application/json", strings.HasSuffix(ct, "+json"), ct == "application/yaml":
+		return true
+	}
+	return false
+}
+
+// compressWriter decides on the first write (or flush) whether to compress, once the
+// status and headers are known.
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding string
+	minSize  int
+	decided  bool
+	enc      encoder
+}
+
+func (w *compressWriter) decide(first []byte) {
+	w.decided = true
+	h := w.Header()
+	status := w.Status()
+	if w.Written() || h.Get("Content-Encoding") != "" || !compressible(h.Get("Content-Type")) ||
+		status == http.StatusNoContent || status == http.StatusNotModified || len(first) < w.minSize {
+		return
+	}
+	h.Set("Content-Encoding", w.encoding)
+	h.Del("Content-Length")
+	if etag := h.Get("ETag"); strings.HasPrefix(etag, `"`) {
+		h.Set("ETag", strings.TrimSuffix(etag, `"`)+"-"+w.encoding+`"`)
+	}
+	w.enc = encoderPools[w.encoding].Get().(encoder)
+	w.enc.Reset(w.ResponseWriter)
+}
+
+func (w *compressWriter) Write(p []byte) (int, error) {
+	if !w.decided {
+		w.decide(p)
+	}
+	if w.enc == nil {
+		return w.ResponseWriter.Write(p)
+	}
+	return w.enc.Write(p)
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decide(nil)
+	}
+	if w.enc != nil {
+		_ = w.enc.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *compressWriter) close() {
+	if w.enc == nil {
+		return
+	}
+	_ = w.enc.Close()
+	w.enc.Reset(io.Discard)
+	encoderPools[w.encoding].Put(w.enc)
+	w.enc = nil
+}
+
+// trimEncodingSuffix undoes the ETag change made by Compression, so validators sent
+// back by clients that received a compressed response still match.
+func trimEncodingSuffix(etag string) string {
+	for _, e := range []string{encodingBrotli, encodingGzip} {
+		if s, ok := strings.CutSuffix(etag, "-"+e+`"`); ok {
+			return s + `"`
+		}
+	}
+	return etag
+}
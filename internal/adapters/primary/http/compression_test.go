@@ -0,0 +1,35 @@
+package http
+
+import "testing"
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{"identity", ""},
+		{"gzip", "gzip"},
+		{"GZIP", "gzip"},
+		{"gzip, br", "br"},
+		{"gzip;q=1.0, br;q=1.0", "br"},
+		{"gzip;q=0.9, br;q=0.8", "gzip"},
+		{"gzip; q=0.5, br; q=0.8", "br"},
+		{"br;Q=0.1, gzip;q=0.2", "gzip"},
+		{"br;level=5;q=0.1, gzip;q=0.2", "gzip"},
+		{"br;q=0", ""},
+		{"br;q=0, gzip", "gzip"},
+		{"gzip;q=0, br;q=0", ""},
+		{"*", "br"},
+		{"*;q=0", ""},
+		{"br;q=0, *", "gzip"},
+		{"*, br;q=0", "gzip"},
+		{"gzip;q=0.5, *;q=0.8", "br"},
+		{"deflate, identity;q=0.5", ""},
+	}
+	for _, tt := range tests {
+		if got := negotiateEncoding(tt.header); got != tt.want {
+			t.Errorf("negotiateEncoding(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package http
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuri/excelize/v2"
+)
+
+// Report formats selectable with ?format= or the Accept header.
+const (
+	formatJSON = "json"
+	formatCSV  = "csv"
+	formatXLSX = "xlsx"
+
+	csvContentType  = "text/csv"
+	xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// reportFormat picks the response format: the format query parameter wins over the
+// Accept header, and JSON is the default. The response varies on Accept either way.
+func reportFormat(c *gin.Context) (string, error) {
+	addVary(c, "Accept")
+	switch f := strings.ToLower(c.Query("format")); f {
+	case formatJSON, formatCSV, formatXLSX:
+		return f, nil
+	case "":
+	default:
+		return "", errInvalidParam("format")
+	}
+	accept := c.GetHeader("Accept")
+	switch {
+	case strings.Contains(accept, csvContentType):
+		return formatCSV, nil
+	case strings.Contains(accept, xlsxContentType):
+		return formatXLSX, nil
+	}
+	return formatJSON, nil
+}
+
+// writeTable sends header and rows as a CSV or XLSX attachment named name. Cells may be
+// strings, ints, or bools; numbers stay numeric in XLSX.
+func writeTable(c *gin.Context, format, name string, header []string, rows [][]any) {
+	switch format {
+	case formatCSV:
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, name))
+		c.Header("Content-Type", csvContentType+"; charset=utf-8")
+		c.Status(http.StatusOK)
+		w := csv.NewWriter(c.Writer)
+		_ = w.Write(header)
+		record := make([]string, len(header))
+		for _, row := range rows {
+			for i, v := range row {
+				record[i] = csvCell(v)
+			}
+			_ = w.Write(record)
+		}
+		w.Flush()
+	case formatXLSX:
+		b, err := xlsxTable(header, rows)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.xlsx"`, name))
+		c.Data(http.StatusOK, xlsxContentType, b)
+	}
+}
+
+// csvCell formats a cell for CSV. Strings a spreadsheet would evaluate as a formula
+// (starting with =, +, -, @, tab, or carriage return) are prefixed with a quote so
+// player and clan names cannot inject one.
+func csvCell(v any) string {
+	switch v := v.(type) {
+	case string:
+		if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+			return "'" + v
+		}
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+func xlsxTable(header []string, rows [][]any) ([]byte, error) {
+	f := excelize.NewFile()
+	defer f.Close()
+	sheet := f.GetSheetName(0)
+	sw, err := f.NewStreamWriter(sheet)
+	if err != nil {
+		return nil, err
+	}
+	cells := make([]any, len(header))
+	for i, h := range header {
+		cells[i] = h
+	}
+	if err := sw.SetRow("A1", cells); err != nil {
+		return nil, err
+	}
+	for i, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+2)
+		if err := sw.SetRow(cell, row); err != nil {
+			return nil, err
+		}
+	}
+	if err := sw.Flush(); err != nil {
+		return nil, err
+	}
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// exportName builds an attachment file name from a tag, keeping only letters and digits.
+func exportName(prefix, tag string) string {
+	tag = strings.TrimPrefix(normalizePlayerTag(tag), "%23")
+	clean := strings.Map(func(r rune) rune {
+		if (r >= '0' && r <= '9') || (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z') {
+			return r
+		}
+		return -1
+	}, tag)
+	return prefix + "-" + strings.ToUpper(clean)
+}
@@ -0,0 +1,28 @@
+package http
+
+import "testing"
+
+func TestCSVCellEscapesFormulas(t *testing.T) {
+	tests := []struct {
+		in   any
+		want string
+	}{
+		{"Giant Gauntlet", "Giant Gauntlet"},
+		{"", ""},
+		{"=HYPERLINK(\"http://x\")", "'=HYPERLINK(\"http://x\")"},
+		{"+1", "'+1"},
+		{"-1", "'-1"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\tcmd", "'\tcmd"},
+		{"\rcmd", "'\rcmd"},
+		{"a=b", "a=b"},
+		{-5, "-5"},
+		{true, "true"},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if got := csvCell(tt.in); got != tt.want {
+			t.Errorf("csvCell(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// fieldSet is a parsed fields= parameter: each key keeps that member of an object,
+// with a nil value keeping it whole and a non-nil one keeping only the listed members
+// below it. Arrays are transparent, so "members.tag" selects the tag of every member.
+type fieldSet map[string]fieldSet
+
+// parseFields parses a comma-separated list of dotted paths, e.g.
+// "clanTag,members.tag,members.spent.shiny". An empty value selects everything.
+func parseFields(v string) (fieldSet, error) {
+	if strings.TrimSpace(v) == "" {
+		return nil, nil
+	}
+	root := fieldSet{}
+	for _, path := range strings.Split(v, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		node := root
+		segments := strings.Split(path, ".")
+		for i, seg := range segments {
+			if seg == "" {
+				return nil, fmt.Errorf("invalid fields parameter: empty segment in %q", path)
+			}
+			if i == len(segments)-1 {
+				// A whole member wins over selections below it.
+				node[seg] = nil
+				break
+			}
+			child, seen := node[seg]
+			if seen && child == nil {
+				// Already kept whole.
+				break
+			}
+			if !seen {
+				child = fieldSet{}
+				node[seg] = child
+			}
+			node = child
+		}
+	}
+	return root, nil
+}
+
+// filter returns body with only the selected members. Numbers are kept verbatim.
+func (fs fieldSet) filter(body []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(fs.apply(v))
+}
+
+func (fs fieldSet) apply(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(fs))
+		for k, sub := range fs {
+			child, ok := v[k]
+			if !ok {
+				continue
+			}
+			if sub == nil {
+				out[k] = child
+			} else {
+				out[k] = sub.apply(child)
+			}
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			out[i] = fs.apply(e)
+		}
+		return out
+	}
+	// A path continuing past a scalar keeps the scalar.
+	return v
+}
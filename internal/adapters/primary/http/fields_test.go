@@ -0,0 +1,84 @@
+package http
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestFieldSelection(t *testing.T) {
+	const body = `{
+		"clanTag": "#C1",
+		"total": {"shiny": 10, "glowy": 2, "starry": 1},
+		"members": [
+			{"tag": "#P1", "name": "Alice", "spent": {"shiny": 7, "glowy": 2, "starry": 1}, "equipments": [{"name": "Rage Vial", "level": 9}]},
+			{"tag": "#P2", "name": "Bob", "spent": {"shiny": 3, "glowy": 0, "starry": 0}, "equipments": []}
+		],
+		"matrix": [[1, 2], [3, 4]],
+		"big": 12345678901234567890
+	}`
+	tests := []struct {
+		name   string
+		fields string
+		want   string
+	}{
+		{"top-level members", "clanTag,total", `{"clanTag":"#C1","total":{"shiny":10,"glowy":2,"starry":1}}`},
+		{"nested member", "total.shiny", `{"total":{"shiny":10}}`},
+		{"through an array", "members.tag", `{"members":[{"tag":"#P1"},{"tag":"#P2"}]}`},
+		{"nested through an array", "members.spent.shiny", `{"members":[{"spent":{"shiny":7}},{"spent":{"shiny":3}}]}`},
+		{"through nested arrays", "members.equipments.level", `{"members":[{"equipments":[{"level":9}]},{"equipments":[]}]}`},
+		{"siblings below an array", "members.tag, members.spent.glowy", `{"members":[{"tag":"#P1","spent":{"glowy":2}},{"tag":"#P2","spent":{"glowy":0}}]}`},
+		{"whole member wins", "members.spent.shiny,members.spent", `{"members":[{"spent":{"shiny":7,"glowy":2,"starry":1}},{"spent":{"shiny":3,"glowy":0,"starry":0}}]}`},
+		{"whole member first", "total,total.shiny", `{"total":{"shiny":10,"glowy":2,"starry":1}}`},
+		{"array of arrays", "matrix.x", `{"matrix":[[1,2],[3,4]]}`},
+		{"path past a scalar", "clanTag.x", `{"clanTag":"#C1"}`},
+		{"unknown members", "nope,members.nope", `{"members":[{},{}]}`},
+	}
+	for _, tt := range tests {
+		fs, err := parseFields(tt.fields)
+		if err != nil {
+			t.Fatalf("%s: parseFields(%q): %v", tt.name, tt.fields, err)
+		}
+		got, err := fs.filter([]byte(body))
+		if err != nil {
+			t.Fatalf("%s: filter: %v", tt.name, err)
+		}
+		if !jsonEqual(t, got, []byte(tt.want)) {
+			t.Errorf("%s: fields=%s gave %s, want %s", tt.name, tt.fields, got, tt.want)
+		}
+	}
+
+	// Numbers are kept verbatim rather than going through float64.
+	fs, _ := parseFields("big")
+	if got, _ := fs.filter([]byte(body)); string(got) != `{"big":12345678901234567890}` {
+		t.Errorf("fields=big gave %s", got)
+	}
+}
+
+func TestParseFields(t *testing.T) {
+	for _, v := range []string{"", " ", ",", "a,,b"} {
+		if _, err := parseFields(v); err != nil {
+			t.Errorf("parseFields(%q): %v", v, err)
+		}
+	}
+	for _, v := range []string{"a..b", ".a", "a.", "a,b."} {
+		if _, err := parseFields(v); err == nil {
+			t.Errorf("parseFields(%q): expected an error", v)
+		}
+	}
+	if fs, _ := parseFields(""); fs != nil {
+		t.Errorf("empty selection = %v, want nil (everything)", fs)
+	}
+}
+
+func jsonEqual(t *testing.T, a, b []byte) bool {
+	t.Helper()
+	var va, vb any
+	if err := json.Unmarshal(a, &va); err != nil {
+		t.Fatalf("unmarshal %s: %v", a, err)
+	}
+	if err := json.Unmarshal(b, &vb); err != nil {
+		t.Fatalf("unmarshal %s: %v", b, err)
+	}
+	return reflect.DeepEqual(va, vb)
+}
@@ -0,0 +1,49 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ab-dauletkhan/coc/internal/application/usecases"
+)
+
+type HealthHandler struct {
+	uc *usecases.HealthUseCase
+}
+
+func NewHealthHandler(uc *usecases.HealthUseCase) *HealthHandler {
+	return &HealthHandler{uc: uc}
+}
+
+func (h *HealthHandler) Register(r *gin.Engine) {
+	r.GET("/healthz", h.healthz)
+	r.GET("/readyz", h.readyz)
+}
+
+// healthCheckTimeout bounds a readiness check, including an upstream probe.
+const healthCheckTimeout = 5 * time.Second
+
+// healthz is a liveness check; with verbose=1 it reports like readyz.
+func (h *HealthHandler) healthz(c *gin.Context) {
+	if v := c.Query("verbose"); v == "" || v == "0" || v == "false" {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+		return
+	}
+	h.readyz(c)
+}
+
+// readyz reports every readiness check, with 503 when any of them fails.
+func (h *HealthHandler) readyz(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), healthCheckTimeout)
+	defer cancel()
+
+	res := h.uc.Check(ctx)
+	status := http.StatusOK
+	if !res.Ready {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, res)
+}
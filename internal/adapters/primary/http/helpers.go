@@ -1,9 +1,56 @@
 package http
 
 import (
+	"fmt"
 	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
 )
 
+const ndjsonContentType = "application/x-ndjson"
+
+// acceptsNDJSON reports whether the client asked for newline-delimited JSON.
+func acceptsNDJSON(c *gin.Context) bool {
+	addVary(c, "Accept")
+	return strings.Contains(c.GetHeader("Accept"), ndjsonContentType)
+}
+
+// addVary lists request headers the response depends on in Vary, once each, so
+// shared caches keep the variants apart.
+func addVary(c *gin.Context, headers ...string) {
+	h := c.Writer.Header()
+	for _, name := range headers {
+		listed := false
+		for _, v := range h.Values("Vary") {
+			for _, f := range strings.Split(v, ",") {
+				if strings.EqualFold(strings.TrimSpace(f), name) {
+					listed = true
+				}
+			}
+		}
+		if !listed {
+			h.Add("Vary", name)
+		}
+	}
+}
+
+// methodRoute fills in the custom method of a route pattern, which gin routes as a
+// parameter starting mid-segment (see PlayerBatchHandler.Register): the pattern
+// "/v1/players:method" becomes "/v1/players:batch". Other parameters are kept.
+func methodRoute(route string, params gin.Params) string {
+	segments := strings.Split(route, "/")
+	for i, seg := range segments {
+		if seg == "" || seg[0] == ':' || seg[0] == '*' {
+			continue
+		}
+		if prefix, name, ok := strings.Cut(seg, ":"); ok {
+			segments[i] = prefix + params.ByName(name)
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
 func normalizePlayerTag(tag string) string {
 	tag = strings.TrimSpace(tag)
 	if tag == "" {
@@ -17,3 +64,19 @@ func normalizePlayerTag(tag string) string {
 	}
 	return "%23" + tag
 }
+
+func errInvalidParam(name string) error {
+	return fmt.Errorf("invalid %s parameter", name)
+}
+
+// parseTimeParam accepts RFC 3339 timestamps or plain dates (midnight UTC).
+// An empty value yields the zero time.
+func parseTimeParam(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, v)
+}
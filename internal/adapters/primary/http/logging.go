@@ -0,0 +1,91 @@
+package http
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ab-dauletkhan/coc/internal/logging"
+)
+
+// requestIDHeader carries the request ID in both directions.
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds client-supplied request IDs; longer ones are replaced.
+const maxRequestIDLength = 128
+
+// RequestID reuses a well-formed incoming X-Request-ID or generates one, echoes it in
+// the response, and stores a logger tagged with it in the request context, where use
+// cases and the upstream client pick it up.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		c.Header(requestIDHeader, id)
+		logger := slog.Default().With("request_id", id)
+		c.Request = c.Request.WithContext(logging.WithLogger(c.Request.Context(), logger))
+		c.Next()
+	}
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if r <= ' ' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// AccessLog logs one record per request with the request logger. Server errors are
+// logged at error level, everything else at info.
+func AccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		if status >= 500 {
+			level = slog.LevelError
+		}
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", status),
+			slog.Int("bytes", max(c.Writer.Size(), 0)),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if errs := c.Errors.String(); errs != "" {
+			attrs = append(attrs, slog.String("error", errs))
+		}
+		logging.FromContext(c.Request.Context()).LogAttrs(c.Request.Context(), level, "request", attrs...)
+	}
+}
+
+// Recovery turns panics into 500 responses and logs them with their stack.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, err any) {
+		logging.FromContext(c.Request.Context()).Error("panic serving request",
+			"error", err, "stack", string(debug.Stack()))
+		c.AbortWithStatus(http.StatusInternalServerError)
+	})
+}
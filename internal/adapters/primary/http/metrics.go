@@ -0,0 +1,28 @@
+package http
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ab-dauletkhan/coc/internal/metrics"
+)
+
+// Metrics records the count and latency of every request by route pattern.
+// Requests matching no route are grouped under "unmatched".
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.ObserveHTTPRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
+
+// RegisterMetrics serves the Prometheus metrics at /metrics.
+func RegisterMetrics(r *gin.Engine) {
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
+}
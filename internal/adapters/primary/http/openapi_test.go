@@ -0,0 +1,288 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	secondary "github.com/ab-dauletkhan/coc/internal/adapters/secondary"
+	"github.com/ab-dauletkhan/coc/internal/application/usecases"
+	"github.com/ab-dauletkhan/coc/internal/catalog"
+	"github.com/ab-dauletkhan/coc/internal/coc"
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
+)
+
+// testAPIKey is an admin key configured in the test router.
+const testAPIKey = "test-secret"
+
+// undocumentedRoutes serve the documentation and metrics rather than the API.
+var undocumentedRoutes = []string{"/metrics", "/docs", "/openapi.yaml", "/docs/openapi.yaml"}
+
+// customMethodParams gives the documented values of mid-segment parameters, which
+// route custom methods.
+var customMethodParams = map[string]gin.Params{
+	"/v1/players:method": {{Key: "method", Value: ":batch"}},
+}
+
+// fakeUpstream serves the Clash of Clans API endpoints the handlers use: clan #C1
+// has #P1, #P2, and #P404, which does not exist.
+func fakeUpstream(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/locations", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"items":[]}`)
+	})
+	mux.HandleFunc("/clans/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "public max-age=60")
+		fmt.Fprint(w, `{"items":[`+
+			`{"tag":"#P1","name":"Alice","role":"leader","townHallLevel":16},`+
+			`{"tag":"#P2","name":"Bob","role":"member","townHallLevel":12},`+
+			`{"tag":"#P404","name":"Gone","role":"admin","townHallLevel":14}]}`)
+	})
+	mux.HandleFunc("/players/", func(w http.ResponseWriter, r *http.Request) {
+		tag, verify := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/players/"), "/verifytoken")
+		switch {
+		case tag == "#P404":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"reason":"notFound"}`)
+		case verify:
+			fmt.Fprintf(w, `{"tag":%q,"token":"t","status":"ok"}`, tag)
+		default:
+			w.Header().Set("Cache-Control", "public max-age=120")
+			fmt.Fprintf(w, `{"tag":%q,"name":"n","townHallLevel":15,"heroEquipment":[`+
+				`{"name":"Rage Vial","level":9,"maxLevel":18,"village":"home"},`+
+				`{"name":"Giant Gauntlet","level":4,"maxLevel":27,"village":"home"}]}`, tag)
+		}
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// newTestRouter wires every handler like cmd/api does, with authentication on and
+// every request and response checked against the spec. It returns the handlers it
+// registered as well.
+func newTestRouter(t *testing.T) (*gin.Engine, []any) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	spec, err := LoadOpenAPISpec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := secondary.NewFileStore(filepath.Join(t.TempDir(), "store.json"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	cat, err := catalog.LoadEquipmentCatalog("../../../../data/hero_equipment.json")
+	if err != nil {
+		t.Fatalf("load catalog: %v", err)
+	}
+	catalogAdapter := secondary.NewCatalogAdapter(cat)
+	cocAdapter := secondary.NewCocAPIAdapter(coc.NewClient(fakeUpstream(t).URL, "token"))
+
+	r := gin.New()
+	r.Use(Recovery(), CacheHints(), Compression(1024), OpenAPIValidation(spec))
+	RegisterMetrics(r)
+
+	apiKeyUC := usecases.NewAPIKeyUseCase(store, usecases.APIKeySettings{
+		Static: []models.APIKey{{
+			ID:         "test",
+			Name:       "test",
+			SecretHash: usecases.HashAPIKeySecret(testAPIKey),
+			Scopes:     []string{models.ScopeAdmin},
+			UserID:     "alice",
+			Static:     true,
+		}},
+	})
+	r.Use(APIKeyAuth(apiKeyUC))
+
+	broker := usecases.NewEventBroker(8)
+	t.Cleanup(broker.Close)
+	recorder := usecases.NewSnapshotRecorder(cocAdapter, store, broker, usecases.SnapshotRetention{})
+	fanOut := usecases.NewFanOut(usecases.FanOutSettings{Workers: 2, MemberTimeout: time.Second})
+	membershipUC := usecases.NewClanMembershipUseCase(store, broker)
+
+	handlers := []interface{ Register(*gin.Engine) }{
+		NewAPIKeyHandler(apiKeyUC),
+		NewHealthHandler(usecases.NewHealthUseCase(cocAdapter, catalogAdapter, store, usecases.HealthSettings{TokenConfigured: true})),
+		NewPlayerEquipmentCostsHandler(usecases.NewPlayerEquipmentCostsUseCase(recorder, catalogAdapter)),
+		NewPlayerHeroEquipmentsHandler(usecases.NewPlayerHeroEquipmentsUseCase(recorder, catalogAdapter)),
+		NewPlayerBatchHandler(usecases.NewPlayerBatchUseCase(recorder, catalogAdapter, fanOut), 5*time.Second),
+		NewPlayerEquipmentHistoryHandler(usecases.NewPlayerEquipmentHistoryUseCase(store, catalogAdapter)),
+		NewClanEquipmentCostsHandler(usecases.NewClanEquipmentCostsUseCase(cocAdapter, recorder, catalogAdapter, fanOut), 5*time.Second),
+		NewClanEquipmentLeaderboardHandler(usecases.NewClanEquipmentLeaderboardUseCase(cocAdapter, recorder, catalogAdapter, fanOut), 5*time.Second),
+		NewClanFamilyHandler(usecases.NewClanFamilyUseCase(store, cocAdapter, recorder, catalogAdapter, fanOut, 3), 5*time.Second),
+		NewAccountLinkHandler(usecases.NewAccountLinkUseCase(store, cocAdapter, recorder, catalogAdapter, fanOut), 5*time.Second, time.Second),
+		NewClanEventsHandler(membershipUC, usecases.NewClanWatchUseCase(broker, store, store)),
+		NewWebhookHandler(usecases.NewWebhookUseCase(store, false)),
+		NewTrackerHandler(usecases.NewTracker(store, cocAdapter, recorder, membershipUC, fanOut, usecases.TrackerSettings{
+			Tick:            time.Second,
+			DefaultInterval: time.Hour,
+			MinInterval:     time.Minute,
+		})),
+	}
+	registered := make([]any, 0, len(handlers))
+	for _, h := range handlers {
+		h.Register(r)
+		if w, ok := h.(interface{ RegisterWrites(*gin.Engine) }); ok {
+			w.RegisterWrites(r)
+		}
+		registered = append(registered, h)
+	}
+	RegisterSwagger(r)
+	return r, registered
+}
+
+// TestRegisteredHandlers guards newTestRouter against handlers added later.
+func TestRegisteredHandlers(t *testing.T) {
+	_, registered := newTestRouter(t)
+	var got []string
+	for _, h := range registered {
+		got = append(got, reflect.TypeOf(h).Elem().Name())
+	}
+
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, ".", func(fi fs.FileInfo) bool { return !strings.HasSuffix(fi.Name(), "_test.go") }, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				fn, ok := decl.(*ast.FuncDecl)
+				if !ok || fn.Recv == nil || fn.Name.Name != "Register" {
+					continue
+				}
+				recv := fn.Recv.List[0].Type.(*ast.StarExpr).X.(*ast.Ident).Name
+				if !slices.Contains(got, recv) {
+					t.Errorf("%s is not registered by newTestRouter", recv)
+				}
+			}
+		}
+	}
+}
+
+func TestRoutesAreDocumented(t *testing.T) {
+	r, _ := newTestRouter(t)
+	spec, err := LoadOpenAPISpec()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	routed := map[string]bool{}
+	for _, route := range r.Routes() {
+		if slices.Contains(undocumentedRoutes, route.Path) {
+			continue
+		}
+		path := specPath(route.Path, customMethodParams[route.Path])
+		routed[route.Method+" "+path] = true
+		item := spec.Paths.Value(path)
+		if item == nil || item.GetOperation(route.Method) == nil {
+			t.Errorf("%s %s is not documented (as %s)", route.Method, route.Path, path)
+		}
+	}
+	for path, item := range spec.Paths.Map() {
+		for method := range item.Operations() {
+			if !routed[method+" "+path] {
+				t.Errorf("%s %s is documented but not routed", method, path)
+			}
+		}
+	}
+}
+
+// TestResponsesMatchSpec exercises every documented JSON operation through the
+// validation middleware, which answers a response not matching the spec with 500.
+func TestResponsesMatchSpec(t *testing.T) {
+	r, _ := newTestRouter(t)
+
+	steps := []struct {
+		method, path, body string
+		want               int
+	}{
+		{"GET", "/healthz", "", 200},
+		{"GET", "/readyz?verbose=1", "", 200},
+		{"GET", "/v1/players/%23P1/hero-equipments", "", 200},
+		{"GET", "/v1/players/%23P1/hero-equipments/costs", "", 200},
+		{"GET", "/v1/players/%23P1/hero-equipments/history", "", 200},
+		{"POST", "/v1/players:batch", `{"tags":["#P1","#P404"],"views":["equipment","costs","plan"]}`, 200},
+		{"GET", "/v1/clans/%23C1/hero-equipments/costs?limit=1", "", 200},
+		{"GET", "/v1/clans/%23C1/hero-equipments/leaderboard?equipment=Rage%20Vial", "", 200},
+		{"GET", "/v1/clans/%23C1/hero-equipments/matrix", "", 200},
+		{"GET", "/v1/clans/%23C1/events", "", 200},
+		{"PUT", "/v1/families/main", `{"clanTags":["#C1"]}`, 200},
+		{"GET", "/v1/families", "", 200},
+		{"GET", "/v1/families/main", "", 200},
+		{"GET", "/v1/families/main/hero-equipments/costs", "", 200},
+		{"GET", "/v1/families/main/hero-equipments/leaderboard?equipment=Rage%20Vial", "", 200},
+		{"GET", "/v1/families/main/hero-equipments/matrix", "", 200},
+		{"DELETE", "/v1/families/main", "", 204},
+		{"POST", "/v1/me/accounts", `{"playerTag":"#P1","token":"t"}`, 201},
+		{"GET", "/v1/me/accounts", "", 200},
+		{"DELETE", "/v1/me/accounts/%23P1", "", 204},
+		{"GET", "/v1/me/usage", "", 200},
+		{"POST", "/v1/tracker/players", `{"tag":"#P1","interval":"1h"}`, 200},
+		{"POST", "/v1/tracker/clans", `{"tag":"#C1"}`, 200},
+		{"GET", "/v1/tracker", "", 200},
+		{"DELETE", "/v1/tracker/players/%23P1", "", 204},
+		{"DELETE", "/v1/tracker/clans/%23C1", "", 204},
+		{"POST", "/v1/webhooks", `{"url":"https://example.com/hook","events":["*"]}`, 201},
+		{"GET", "/v1/webhooks", "", 200},
+		{"POST", "/v1/api-keys", `{"name":"bot","scopes":["read"]}`, 201},
+		{"GET", "/v1/api-keys", "", 200},
+		{"GET", "/v1/api-keys/test/usage", "", 200},
+	}
+	for _, s := range steps {
+		w := serve(r, s.method, s.path, s.body)
+		if w.Code != s.want {
+			t.Errorf("%s %s: status %d, want %d: %s", s.method, s.path, w.Code, s.want, w.Body)
+		}
+	}
+}
+
+func TestValidationRejectsUndocumentedOutput(t *testing.T) {
+	spec, err := LoadOpenAPISpec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := gin.New()
+	r.Use(OpenAPIValidation(spec))
+	r.GET("/v1/families", func(c *gin.Context) {
+		respondJSON(c, http.StatusOK, gin.H{"items": "not-a-list"})
+	})
+	r.GET("/v1/families/:name", func(c *gin.Context) {
+		respondJSON(c, http.StatusOK, gin.H{"name": c.Param("name"), "clanTags": []string{"#C1"}})
+	})
+
+	if w := serve(r, "GET", "/v1/families", ""); w.Code != http.StatusInternalServerError {
+		t.Errorf("mismatching response: status %d, want 500: %s", w.Code, w.Body)
+	}
+	if w := serve(r, "GET", "/v1/families/main", ""); w.Code != http.StatusOK {
+		t.Errorf("matching response: status %d, want 200: %s", w.Code, w.Body)
+	}
+}
+
+func serve(r *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequestWithContext(context.Background(), method, path, bytes.NewBufferString(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Authorization", "Bearer "+testAPIKey)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
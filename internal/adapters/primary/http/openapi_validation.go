@@ -0,0 +1,151 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/gin-gonic/gin"
+
+	"github.com/ab-dauletkhan/coc/internal/logging"
+)
+
+// LoadOpenAPISpec parses and validates the embedded OpenAPI document.
+func LoadOpenAPISpec() (*openapi3.T, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(openapiYAML)
+	if err != nil {
+		return nil, fmt.Errorf("parse OpenAPI spec: %w", err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI spec: %w", err)
+	}
+	return doc, nil
+}
+
+// specPath converts a gin route pattern to the path template used in the spec, e.g.
+// "/v1/players/:tag/hero-equipments" to "/v1/players/{tag}/hero-equipments". A
+// parameter in the middle of a segment routes custom methods such as
+// "/v1/players:batch" (see PlayerBatchHandler), so it is replaced by its value.
+func specPath(route string, params gin.Params) string {
+	segments := strings.Split(methodRoute(route, params), "/")
+	for i, seg := range segments {
+		if seg != "" && (seg[0] == ':' || seg[0] == '*') {
+			segments[i] = "{" + seg[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// OpenAPIValidation checks requests and JSON responses of the routes documented in
+// doc against it. An invalid request is rejected with 400 before reaching the
+// handler; an invalid response is logged and replaced with a 500, so a handler
+// drifting from the spec fails loudly. Statuses the spec does not document, other
+// content types, streams, and responses trimmed by the fields parameter are passed
+// through unchecked. Meant for tests and development: responses are buffered.
+func OpenAPIValidation(doc *openapi3.T) gin.HandlerFunc {
+	options := &openapi3filter.Options{
+		AuthenticationFunc:  openapi3filter.NoopAuthenticationFunc,
+		SkipSettingDefaults: true,
+	}
+	return func(c *gin.Context) {
+		path := specPath(c.FullPath(), c.Params)
+		item := doc.Paths.Value(path)
+		if item == nil || item.GetOperation(c.Request.Method) == nil {
+			c.Next()
+			return
+		}
+		params := make(map[string]string, len(c.Params))
+		for _, p := range c.Params {
+			params[p.Key] = p.Value
+		}
+		input := &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: params,
+			Route: &routers.Route{
+				Spec:      doc,
+				Path:      path,
+				PathItem:  item,
+				Method:    c.Request.Method,
+				Operation: item.GetOperation(c.Request.Method),
+			},
+			Options: options,
+		}
+		if err := openapi3filter.ValidateRequest(c.Request.Context(), input); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		w := &validatingWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+		if !w.buffering {
+			return
+		}
+		if c.Query("fields") == "" {
+			res := &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: input,
+				Status:                 w.Status(),
+				Header:                 w.Header(),
+				Options:                options,
+			}
+			if err := openapi3filter.ValidateResponse(c.Request.Context(), res.SetBodyBytes(w.body.Bytes())); err != nil {
+				logging.FromContext(c.Request.Context()).Error("response does not match the OpenAPI spec",
+					"route", c.FullPath(), "status", w.Status(), "error", err)
+				w.Header().Del("ETag")
+				w.Header().Del("Last-Modified")
+				w.Header().Set("Cache-Control", "no-store")
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "response does not match the OpenAPI spec: " + err.Error()})
+				return
+			}
+		}
+		_, _ = w.ResponseWriter.Write(w.body.Bytes())
+	}
+}
+
+// validatingWriter holds back JSON bodies until the handler is done, so they can be
+// checked before anything is sent. Other bodies, and anything flushed, pass through.
+type validatingWriter struct {
+	gin.ResponseWriter
+	decided   bool
+	buffering bool
+	body      bytes.Buffer
+}
+
+func (w *validatingWriter) decide() {
+	w.decided = true
+	ct, _, _ := strings.Cut(w.Header().Get("Content-Type"), ";")
+	w.buffering = strings.TrimSpace(ct) == "application/json"
+}
+
+func (w *validatingWriter) Write(p []byte) (int, error) {
+	if !w.decided {
+		w.decide()
+	}
+	if w.buffering {
+		return w.body.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *validatingWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *validatingWriter) Flush() {
+	if !w.decided {
+		w.decided = true
+	}
+	if !w.buffering {
+		w.ResponseWriter.Flush()
+	}
+}
+
+func (w *validatingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
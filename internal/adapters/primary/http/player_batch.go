@@ -0,0 +1,59 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ab-dauletkhan/coc/internal/application/usecases"
+)
+
+type PlayerBatchHandler struct {
+	uc *usecases.PlayerBatchUseCase
+	// timeout bounds a whole batch; each player is also bounded by its own fetch timeout.
+	timeout time.Duration
+}
+
+func NewPlayerBatchHandler(uc *usecases.PlayerBatchUseCase, timeout time.Duration) *PlayerBatchHandler {
+	return &PlayerBatchHandler{uc: uc, timeout: timeout}
+}
+
+func (h *PlayerBatchHandler) Register(r *gin.Engine) {
+	// gin treats ':' as the start of a parameter even mid-segment, so the custom method
+	// suffix is routed as a parameter and matched in the handler.
+	r.POST("/v1/players:method", h.dispatch)
+}
+
+func (h *PlayerBatchHandler) dispatch(c *gin.Context) {
+	if c.Param("method") != ":batch" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+	h.batch(c)
+}
+
+func (h *PlayerBatchHandler) batch(c *gin.Context) {
+	var body struct {
+		Tags  []string `json:"tags"`
+		Views []string `json:"views"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.timeout)
+	defer cancel()
+
+	res, status, err := h.uc.Execute(ctx, body.Tags, body.Views)
+	if err != nil {
+		if status == 0 {
+			status = http.StatusBadGateway
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	respondJSON(c, http.StatusOK, res)
+}
@@ -0,0 +1,70 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/ab-dauletkhan/coc/internal/application/usecases"
+)
+
+func TestPlayerBatchRoute(t *testing.T) {
+	r, _ := newTestRouter(t)
+
+	w := serve(r, "POST", "/v1/players:batch", `{"tags":["#P1","p1","#P404"],"views":["plan"]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("POST /v1/players:batch: status %d, want 200: %s", w.Code, w.Body)
+	}
+	var res usecases.PlayerBatchResult
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Requested != 2 || res.Succeeded != 1 || res.Failed != 1 || len(res.Results) != 2 {
+		t.Fatalf("result = %+v, want #P1 merged with p1 and #P404 failed", res)
+	}
+	for _, item := range res.Results {
+		if item.Equipment != nil || item.Costs != nil {
+			t.Errorf("%s: got views that were not requested: %+v", item.Tag, item)
+		}
+		if ok := item.Tag == "#P1"; ok != (item.Plan != nil) {
+			t.Errorf("%s: plan = %+v", item.Tag, item.Plan)
+		}
+	}
+
+	for _, path := range []string{"/v1/players:other", "/v1/players:"} {
+		if w := serve(r, "POST", path, `{"tags":["#P1"]}`); w.Code != http.StatusNotFound {
+			t.Errorf("POST %s: status %d, want 404: %s", path, w.Code, w.Body)
+		}
+	}
+}
+
+func TestPlayerBatchRequests(t *testing.T) {
+	r, _ := newTestRouter(t)
+
+	w := serve(r, "POST", "/v1/players:batch", `{"tags":["#P1"]}`)
+	var res usecases.PlayerBatchResult
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil || len(res.Results) != 1 {
+		t.Fatalf("status %d: %v: %s", w.Code, err, w.Body)
+	}
+	if item := res.Results[0]; item.Equipment == nil || item.Costs == nil || item.Plan != nil {
+		t.Errorf("default views = %+v, want equipment and costs", item)
+	}
+
+	tags := make([]string, 101)
+	for i := range tags {
+		tags[i] = fmt.Sprintf("#P%d", i)
+	}
+	tooMany, _ := json.Marshal(map[string][]string{"tags": tags})
+	for name, body := range map[string]string{
+		"no tags":      `{"tags":[]}`,
+		"blank tags":   `{"tags":["", " "]}`,
+		"unknown view": `{"tags":["#P1"],"views":["clan"]}`,
+		"101 tags":     string(tooMany),
+		"not JSON":     `tags=#P1`,
+	} {
+		if w := serve(r, "POST", "/v1/players:batch", body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400: %s", name, w.Code, w.Body)
+		}
+	}
+}
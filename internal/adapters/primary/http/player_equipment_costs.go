@@ -3,6 +3,7 @@ package http
 import (
 	"context"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -29,6 +30,11 @@ func (h *PlayerEquipmentCostsHandler) get(c *gin.Context) {
 		return
 	}
 	nTag := normalizePlayerTag(tag)
+	format, err := reportFormat(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 6*time.Second)
 	defer cancel()
@@ -45,5 +51,21 @@ func (h *PlayerEquipmentCostsHandler) get(c *gin.Context) {
 		c.Status(status)
 		return
 	}
-	c.JSON(http.StatusOK, res)
+	if format != formatJSON {
+		writeTable(c, format, exportName("equipment-costs", nTag), equipmentCostsHeader, equipmentCostsRows(res))
+		return
+	}
+	respondJSON(c, http.StatusOK, res)
+}
+
+var equipmentCostsHeader = []string{"playerTag", "equipmentId", "equipment", "rarity", "level", "shiny", "glowy", "starry"}
+
+// equipmentCostsRows keeps the result's catalog ID order.
+func equipmentCostsRows(res usecases.PlayerEquipmentCostsResult) [][]any {
+	tag := "#" + strings.TrimPrefix(res.PlayerTag, "%23")
+	rows := make([][]any, len(res.Equipments))
+	for i, e := range res.Equipments {
+		rows[i] = []any{tag, e.ID, e.Name, e.Rarity, e.Level, e.Spent.Shiny, e.Spent.Glowy, e.Spent.Starry}
+	}
+	return rows
 }
@@ -0,0 +1,46 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ab-dauletkhan/coc/internal/application/usecases"
+)
+
+type PlayerEquipmentHistoryHandler struct {
+	uc *usecases.PlayerEquipmentHistoryUseCase
+}
+
+func NewPlayerEquipmentHistoryHandler(uc *usecases.PlayerEquipmentHistoryUseCase) *PlayerEquipmentHistoryHandler {
+	return &PlayerEquipmentHistoryHandler{uc: uc}
+}
+
+func (h *PlayerEquipmentHistoryHandler) Register(r *gin.Engine) {
+	r.GET("/v1/players/:tag/hero-equipments/history", h.get)
+}
+
+func (h *PlayerEquipmentHistoryHandler) get(c *gin.Context) {
+	tag := c.Param("tag")
+	if tag == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing tag"})
+		return
+	}
+	from, err := parseTimeParam(c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errInvalidParam("from").Error()})
+		return
+	}
+	to, err := parseTimeParam(c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errInvalidParam("to").Error()})
+		return
+	}
+
+	res, status, err := h.uc.Execute(c.Request.Context(), tag, from, to)
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	respondJSON(c, http.StatusOK, res)
+}
@@ -0,0 +1,46 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/ab-dauletkhan/coc/internal/application/usecases"
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
+)
+
+func TestPlayerEquipmentHistoryRoute(t *testing.T) {
+	r, _ := newTestRouter(t)
+
+	const history = "/v1/players/%23P1/hero-equipments/history"
+	if w := serve(r, "GET", history, ""); w.Code != http.StatusNotFound {
+		t.Errorf("history before any snapshot: status %d, want 404: %s", w.Code, w.Body)
+	}
+	// Fetching the player through the recorder stores a snapshot.
+	if w := serve(r, "GET", "/v1/players/%23P1/hero-equipments", ""); w.Code != http.StatusOK {
+		t.Fatalf("fetch player: status %d: %s", w.Code, w.Body)
+	}
+
+	w := serve(r, "GET", "/v1/players/p1/hero-equipments/history", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("history: status %d, want 200: %s", w.Code, w.Body)
+	}
+	var res usecases.PlayerEquipmentHistoryResult
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.PlayerTag != "#P1" || res.Snapshots != 1 || len(res.Intervals) != 0 || res.Spent != (models.OreTotals{}) {
+		t.Errorf("history of one snapshot = %+v, want #P1 with nothing spent", res)
+	}
+
+	for query, want := range map[string]int{
+		"?to=2000-01-01":                                     http.StatusNotFound,
+		"?from=2030-01-02&to=2030-01-01":                     http.StatusBadRequest,
+		"?from=2030-01-01T00:00:00Z&to=2030-01-01T00:00:00Z": http.StatusBadRequest,
+		"?from=yesterday":                                    http.StatusBadRequest,
+	} {
+		if w := serve(r, "GET", history+query, ""); w.Code != want {
+			t.Errorf("history%s: status %d, want %d: %s", query, w.Code, want, w.Body)
+		}
+	}
+}
@@ -3,6 +3,8 @@ package http
 import (
 	"context"
 	"net/http"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -29,6 +31,11 @@ func (h *PlayerHeroEquipmentsHandler) get(c *gin.Context) {
 		return
 	}
 	nTag := normalizePlayerTag(tag)
+	format, err := reportFormat(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 6*time.Second)
 	defer cancel()
@@ -45,5 +52,28 @@ func (h *PlayerHeroEquipmentsHandler) get(c *gin.Context) {
 		c.Status(status)
 		return
 	}
-	c.JSON(http.StatusOK, res)
+	if format != formatJSON {
+		writeTable(c, format, exportName("hero-equipments", nTag), heroEquipmentsHeader, heroEquipmentsRows(res))
+		return
+	}
+	respondJSON(c, http.StatusOK, res)
+}
+
+var heroEquipmentsHeader = []string{"playerTag", "equipmentId", "equipment", "available", "level", "maxLevel"}
+
+// heroEquipmentsRows lists available and unavailable equipment together, ordered by catalog ID.
+func heroEquipmentsRows(res usecases.PlayerHeroEquipmentsResult) [][]any {
+	all := append(append([]usecases.Equipment{}, res.Available...), res.Unavailable...)
+	sort.SliceStable(all, func(i, j int) bool {
+		if all[i].ID != all[j].ID {
+			return all[i].ID < all[j].ID
+		}
+		return all[i].Name < all[j].Name
+	})
+	tag := "#" + strings.TrimPrefix(res.PlayerTag, "%23")
+	rows := make([][]any, len(all))
+	for i, e := range all {
+		rows[i] = []any{tag, e.ID, e.Name, e.Available, e.Level, e.MaxLevel}
+	}
+	return rows
 }
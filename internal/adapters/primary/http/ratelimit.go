@@ -0,0 +1,59 @@
+package http
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ab-dauletkhan/coc/internal/metrics"
+	"github.com/ab-dauletkhan/coc/internal/ratelimit"
+)
+
+// InboundRateLimit charges every request to the bucket of its client IP, as resolved
+// by gin from the trusted proxies, and rejects it with 429 when the bucket cannot
+// cover its cost. weights maps route pattern prefixes (e.g. "/v1/clans/:tag/") to
+// costs; the longest matching prefix wins, other routes cost 1, and a zero weight
+// exempts a route. Custom methods are matched by name, e.g. "/v1/players:batch". Responses carry RateLimit-Limit, RateLimit-Remaining,
+// RateLimit-Reset, and RateLimit-Policy headers.
+func InboundRateLimit(limiter *ratelimit.Keyed, weights map[string]float64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := methodRoute(c.FullPath(), c.Params)
+		cost := routeWeight(route, weights)
+		if cost == 0 {
+			c.Next()
+			return
+		}
+		d := limiter.Take(c.ClientIP(), cost)
+		if d.Limit == 0 {
+			c.Next()
+			return
+		}
+		c.Header("RateLimit-Limit", strconv.Itoa(d.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(d.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(int(math.Ceil(d.Reset.Seconds()))))
+		c.Header("RateLimit-Policy", strconv.Itoa(d.Limit)+";w="+strconv.Itoa(int(math.Ceil(d.Window.Seconds()))))
+		if !d.Allowed {
+			if route == "" {
+				route = "unmatched"
+			}
+			metrics.RateLimited(route)
+			c.Header("Retry-After", strconv.Itoa(retryAfterSeconds(d.RetryAfter)))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "too many requests"})
+			return
+		}
+		c.Next()
+	}
+}
+
+func routeWeight(route string, weights map[string]float64) float64 {
+	cost, matched := 1.0, -1
+	for prefix, w := range weights {
+		if len(prefix) > matched && strings.HasPrefix(route, prefix) {
+			cost, matched = w, len(prefix)
+		}
+	}
+	return cost
+}
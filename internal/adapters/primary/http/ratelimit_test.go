@@ -0,0 +1,37 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ab-dauletkhan/coc/internal/config"
+	"github.com/ab-dauletkhan/coc/internal/ratelimit"
+)
+
+func TestInboundRateLimitWeighsCustomMethods(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(InboundRateLimit(ratelimit.NewKeyed(1, 100), config.Load().InboundRouteWeights))
+	r.POST("/v1/players:method", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/v1/players/:tag", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	steps := []struct {
+		method, path string
+		remaining    string
+	}{
+		{"POST", "/v1/players:batch", "90"},
+		{"POST", "/v1/players:other", "89"},
+		{"GET", "/v1/players/%23P1", "88"},
+		{"POST", "/v1/players:batch", "78"},
+	}
+	for _, s := range steps {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(s.method, s.path, nil))
+		if got := w.Header().Get("RateLimit-Remaining"); got != s.remaining {
+			t.Errorf("%s %s: RateLimit-Remaining = %q, want %s", s.method, s.path, got, s.remaining)
+		}
+	}
+}
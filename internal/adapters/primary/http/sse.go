@@ -0,0 +1,41 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sseHeartbeat is how often idle event streams send a comment so proxies keep them open.
+const sseHeartbeat = 15 * time.Second
+
+// beginSSE sends the event stream response headers right away, before any event.
+func beginSSE(c *gin.Context) {
+	clearWriteDeadline(c)
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// Disable response buffering in nginx-style proxies.
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+}
+
+// sendSSE writes one event and flushes it to the client.
+func sendSSE(c *gin.Context, event string, data any) {
+	c.SSEvent(event, data)
+	c.Writer.Flush()
+}
+
+func sendSSEHeartbeat(c *gin.Context) {
+	fmt.Fprint(c.Writer, ": keepalive\n\n")
+	c.Writer.Flush()
+}
+
+// clearWriteDeadline lifts the server's write timeout for a streamed response, which
+// is bounded by its own context instead.
+func clearWriteDeadline(c *gin.Context) {
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+}
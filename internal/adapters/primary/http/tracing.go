@@ -0,0 +1,16 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+)
+
+// Tracing starts a server span per request, continuing the trace of incoming W3C
+// traceparent headers. Scrapes and health checks are not traced.
+func Tracing(service string) gin.HandlerFunc {
+	return otelgin.Middleware(service, otelgin.WithFilter(func(r *http.Request) bool {
+		return r.URL.Path != "/metrics" && r.URL.Path != "/healthz" && r.URL.Path != "/readyz"
+	}))
+}
@@ -0,0 +1,80 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ab-dauletkhan/coc/internal/application/usecases"
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
+)
+
+type TrackerHandler struct {
+	tracker *usecases.Tracker
+}
+
+func NewTrackerHandler(tracker *usecases.Tracker) *TrackerHandler {
+	return &TrackerHandler{tracker: tracker}
+}
+
+func (h *TrackerHandler) Register(r *gin.Engine) {
+	r.GET("/v1/tracker", h.status)
+}
+
+// RegisterWrites adds the routes that add and remove tracked entries, which need the
+// write scope. Register them only when APIKeyAuth is in use.
+func (h *TrackerHandler) RegisterWrites(r *gin.Engine) {
+	g := r.Group("/v1/tracker", RequireScope(models.ScopeWrite))
+	g.POST("/players", h.register(models.TrackedPlayer))
+	g.POST("/clans", h.register(models.TrackedClan))
+	g.DELETE("/players/:tag", h.unregister(models.TrackedPlayer))
+	g.DELETE("/clans/:tag", h.unregister(models.TrackedClan))
+}
+
+func (h *TrackerHandler) status(c *gin.Context) {
+	res, status, err := h.tracker.Status(c.Request.Context())
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	respondJSON(c, http.StatusOK, res)
+}
+
+func (h *TrackerHandler) register(kind models.TrackedKind) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			Tag      string `json:"tag"`
+			Interval string `json:"interval"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		var interval time.Duration
+		if body.Interval != "" {
+			var err error
+			if interval, err = time.ParseDuration(body.Interval); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": errInvalidParam("interval").Error()})
+				return
+			}
+		}
+		res, status, err := h.tracker.Register(c.Request.Context(), kind, body.Tag, interval)
+		if err != nil {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		respondJSON(c, http.StatusOK, res)
+	}
+}
+
+func (h *TrackerHandler) unregister(kind models.TrackedKind) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		status, err := h.tracker.Unregister(c.Request.Context(), kind, c.Param("tag"))
+		if err != nil {
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
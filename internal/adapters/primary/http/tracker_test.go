@@ -0,0 +1,102 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ab-dauletkhan/coc/internal/application/usecases"
+)
+
+type trackerStep struct {
+	method, path, body string
+	want               int
+}
+
+func runTrackerSteps(t *testing.T, r *gin.Engine, steps []trackerStep) {
+	t.Helper()
+	for _, s := range steps {
+		if w := serve(r, s.method, s.path, s.body); w.Code != s.want {
+			t.Errorf("%s %s %s: status %d, want %d: %s", s.method, s.path, s.body, w.Code, s.want, w.Body)
+		}
+	}
+}
+
+func trackerStatus(t *testing.T, r *gin.Engine) usecases.TrackerStatusResult {
+	t.Helper()
+	w := serve(r, "GET", "/v1/tracker", "")
+	var res usecases.TrackerStatusResult
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("GET /v1/tracker: %v: %s", err, w.Body)
+	}
+	return res
+}
+
+func TestTrackerRegistration(t *testing.T) {
+	r, _ := newTestRouter(t)
+
+	// The test router's minimum interval is a minute.
+	runTrackerSteps(t, r, []trackerStep{
+		{"POST", "/v1/tracker/players", `{"tag":"#P1","interval":"1h"}`, 200},
+		{"POST", "/v1/tracker/players", `{"tag":"p1","interval":"2h"}`, 200},
+		{"POST", "/v1/tracker/clans", `{"tag":"#C1"}`, 200},
+		{"POST", "/v1/tracker/players", `{"tag":"#P2","interval":"1m"}`, 200},
+		{"POST", "/v1/tracker/players", `{"tag":"#P3","interval":"30s"}`, 400},
+		{"POST", "/v1/tracker/players", `{"tag":"#P3","interval":"soon"}`, 400},
+		{"POST", "/v1/tracker/players", `{"interval":"1h"}`, 400},
+	})
+
+	// Registering #P1 again, under another spelling, updated it rather than adding
+	// a second target.
+	want := map[string]string{"#P1": "2h0m0s", "#C1": "1h0m0s", "#P2": "1m0s"}
+	targets := trackerStatus(t, r).Targets
+	if len(targets) != len(want) {
+		t.Fatalf("targets = %+v, want %v", targets, want)
+	}
+	for _, tt := range targets {
+		if tt.Interval != want[tt.Tag] {
+			t.Errorf("%s %s: interval %s, want %s", tt.Kind, tt.Tag, tt.Interval, want[tt.Tag])
+		}
+	}
+
+	runTrackerSteps(t, r, []trackerStep{
+		{"DELETE", "/v1/tracker/players/p1", "", 204},
+		{"DELETE", "/v1/tracker/players/%23P1", "", 404},
+		{"DELETE", "/v1/tracker/players/%23C1", "", 404},
+		{"DELETE", "/v1/tracker/clans/%23C1", "", 204},
+	})
+	if targets := trackerStatus(t, r).Targets; len(targets) != 1 || targets[0].Tag != "#P2" {
+		t.Errorf("targets after unregistering = %+v, want only #P2", targets)
+	}
+}
+
+func TestTrackerWritesNeedWriteScope(t *testing.T) {
+	r, _ := newTestRouter(t)
+	w := serve(r, "POST", "/v1/api-keys", `{"name":"reader","scopes":["read"]}`)
+	var key usecases.APIKey
+	if err := json.Unmarshal(w.Body.Bytes(), &key); err != nil || key.Secret == "" {
+		t.Fatalf("create read key: %v: %s", err, w.Body)
+	}
+
+	for _, s := range []trackerStep{
+		{"GET", "/v1/tracker", "", 200},
+		{"POST", "/v1/tracker/players", `{"tag":"#P1"}`, 403},
+		{"DELETE", "/v1/tracker/clans/%23C1", "", 403},
+	} {
+		req := httptest.NewRequestWithContext(context.Background(), s.method, s.path, strings.NewReader(s.body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+key.Secret)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != s.want {
+			t.Errorf("%s %s with a read key: status %d, want %d: %s", s.method, s.path, w.Code, s.want, w.Body)
+		}
+	}
+	if targets := trackerStatus(t, r).Targets; len(targets) != 0 {
+		t.Errorf("a read key registered targets: %+v", targets)
+	}
+}
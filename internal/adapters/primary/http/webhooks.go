@@ -0,0 +1,92 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ab-dauletkhan/coc/internal/application/usecases"
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
+)
+
+type WebhookHandler struct {
+	uc *usecases.WebhookUseCase
+}
+
+func NewWebhookHandler(uc *usecases.WebhookUseCase) *WebhookHandler {
+	return &WebhookHandler{uc: uc}
+}
+
+// Register adds the subscription routes, which need the admin scope. Register them
+// only when APIKeyAuth is in use.
+func (h *WebhookHandler) Register(r *gin.Engine) {
+	g := r.Group("/v1/webhooks", RequireScope(models.ScopeAdmin))
+	g.GET("", h.list)
+	g.POST("", h.create)
+	g.GET("/:id", h.get)
+	g.DELETE("/:id", h.delete)
+	g.GET("/:id/deliveries", h.deliveries)
+}
+
+func (h *WebhookHandler) list(c *gin.Context) {
+	res, status, err := h.uc.List(c.Request.Context())
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	respondJSON(c, http.StatusOK, gin.H{"webhooks": res})
+}
+
+func (h *WebhookHandler) create(c *gin.Context) {
+	var body struct {
+		URL    string   `json:"url"`
+		Secret string   `json:"secret"`
+		Events []string `json:"events"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	res, status, err := h.uc.Create(c.Request.Context(), body.URL, body.Secret, body.Events)
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	respondJSON(c, http.StatusCreated, res)
+}
+
+func (h *WebhookHandler) get(c *gin.Context) {
+	res, status, err := h.uc.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	respondJSON(c, http.StatusOK, res)
+}
+
+func (h *WebhookHandler) delete(c *gin.Context) {
+	status, err := h.uc.Delete(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *WebhookHandler) deliveries(c *gin.Context) {
+	limit := 0
+	if v := c.Query("limit"); v != "" {
+		var err error
+		if limit, err = strconv.Atoi(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errInvalidParam("limit").Error()})
+			return
+		}
+	}
+	res, status, err := h.uc.Deliveries(c.Request.Context(), c.Param("id"), limit)
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	respondJSON(c, http.StatusOK, res)
+}
@@ -2,59 +2,99 @@ package secondary
 
 import (
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/ab-dauletkhan/coc/internal/catalog"
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
 	"github.com/ab-dauletkhan/coc/internal/domain/ports"
+	"github.com/ab-dauletkhan/coc/internal/metrics"
 )
 
 // CatalogAdapter adapts internal/catalog to the domain CatalogRepository port.
 type CatalogAdapter struct {
-	cat catalog.EquipmentCatalog
+	mu       sync.RWMutex
+	cat      catalog.EquipmentCatalog
+	loadedAt time.Time
 }
 
 func NewCatalogAdapter(cat catalog.EquipmentCatalog) *CatalogAdapter {
-	return &CatalogAdapter{cat: cat}
+	a := &CatalogAdapter{cat: cat}
+	if len(cat.Items) > 0 {
+		a.loadedAt = time.Now()
+	}
+	return a
 }
 
-func (a *CatalogAdapter) GetRarity(name string) string {
-	upper := strings.ToUpper(strings.TrimSpace(name))
-	for _, it := range a.cat.Items {
-		if strings.ToUpper(it.Name) == upper {
-			return it.Rarity
-		}
+// Replace swaps in a freshly loaded catalog; lookups in progress keep the old one.
+func (a *CatalogAdapter) Replace(cat catalog.EquipmentCatalog) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.cat = cat
+	a.loadedAt = time.Now()
+}
+
+func (a *CatalogAdapter) Info() models.CatalogInfo {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return models.CatalogInfo{
+		Items:    len(a.cat.Items),
+		Version:  a.cat.Version,
+		Checksum: a.cat.Checksum,
+		LoadedAt: a.loadedAt,
 	}
-	return ""
 }
 
-func (a *CatalogAdapter) GetID(name string) int {
+func (a *CatalogAdapter) catalog() catalog.EquipmentCatalog {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.cat
+}
+
+// find looks up an equipment by name, ignoring case, and counts misses.
+func (a *CatalogAdapter) find(name string) (catalog.Equipment, bool) {
 	upper := strings.ToUpper(strings.TrimSpace(name))
-	for _, it := range a.cat.Items {
+	for _, it := range a.catalog().Items {
 		if strings.ToUpper(it.Name) == upper {
-			return it.ID
+			return it, true
 		}
 	}
-	return 0
+	metrics.UnknownEquipment(strings.TrimSpace(name))
+	return catalog.Equipment{}, false
+}
+
+func (a *CatalogAdapter) GetRarity(name string) string {
+	it, _ := a.find(name)
+	return it.Rarity
+}
+
+func (a *CatalogAdapter) GetID(name string) int {
+	it, _ := a.find(name)
+	return it.ID
 }
 
 func (a *CatalogAdapter) CostsCommon() []ports.OreCost {
-	out := make([]ports.OreCost, len(a.cat.CommonCostsPerLevel))
-	for i, c := range a.cat.CommonCostsPerLevel {
+	costs := a.catalog().CommonCostsPerLevel
+	out := make([]ports.OreCost, len(costs))
+	for i, c := range costs {
 		out[i] = ports.OreCost{Shiny: c.Shiny, Glowy: c.Glowy, Starry: c.Starry}
 	}
 	return out
 }
 
 func (a *CatalogAdapter) CostsEpic() []ports.OreCost {
-	out := make([]ports.OreCost, len(a.cat.EpicCostsPerLevel))
-	for i, c := range a.cat.EpicCostsPerLevel {
+	costs := a.catalog().EpicCostsPerLevel
+	out := make([]ports.OreCost, len(costs))
+	for i, c := range costs {
 		out[i] = ports.OreCost{Shiny: c.Shiny, Glowy: c.Glowy, Starry: c.Starry}
 	}
 	return out
 }
 
 func (a *CatalogAdapter) ListEquipmentNames() []string {
-	out := make([]string, 0, len(a.cat.Items))
-	for _, it := range a.cat.Items {
+	items := a.catalog().Items
+	out := make([]string, 0, len(items))
+	for _, it := range items {
 		out = append(out, it.Name)
 	}
 	return out
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/ab-dauletkhan/coc/internal/coc"
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
 )
 
 // CocAPIAdapter adapts internal/coc.Client to domain ports.
@@ -20,3 +21,23 @@ func (a *CocAPIAdapter) GetPlayerRaw(ctx context.Context, tag string) ([]byte, i
 func (a *CocAPIAdapter) GetClanMembersRaw(ctx context.Context, tag string) ([]byte, int, error) {
 	return a.client.GetClanMembersRaw(ctx, tag)
 }
+
+func (a *CocAPIAdapter) VerifyPlayerTokenRaw(ctx context.Context, tag, token string) ([]byte, int, error) {
+	return a.client.VerifyPlayerToken(ctx, tag, token)
+}
+
+func (a *CocAPIAdapter) UpstreamHealth() models.UpstreamHealth {
+	h := a.client.Health()
+	return models.UpstreamHealth{
+		LastSuccessAt:   h.LastSuccessAt,
+		LastErrorAt:     h.LastErrorAt,
+		LastError:       h.LastError,
+		LastErrorStatus: h.LastErrorStatus,
+		Circuit:         h.Circuit,
+	}
+}
+
+func (a *CocAPIAdapter) Probe(ctx context.Context) error {
+	_, err := a.client.Ping(ctx)
+	return err
+}
@@ -0,0 +1,225 @@
+package secondary
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileStore is a small JSON-file backed store implementing the persistence ports.
+// The whole document is kept in memory. Configuration-like changes (families, links)
+// are written through immediately; high-volume data such as tracker runs only marks
+// the store dirty and is written by a background flusher, and on Flush or Close.
+// Player snapshots are kept in a file per player next to the document (see
+// snapshotFiles) and flushed the same way.
+type FileStore struct {
+	path      string
+	snapshots *snapshotFiles
+
+	mu    sync.Mutex
+	data  fileStoreData
+	dirty bool
+	// writeErr is the error of the last failed write, cleared by a successful one.
+	writeErr error
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+type fileStoreData struct {
+	Families map[string]familyRecord `json:"families,omitempty"`
+	// AccountLinks is keyed by player tag so a tag has a single owner.
+	AccountLinks map[string]accountLinkRecord `json:"accountLinks,omitempty"`
+	// Snapshots is only read, from documents written before snapshots moved to their
+	// own files.
+	Snapshots map[string][]snapshotRecord `json:"snapshots,omitempty"`
+	// Tracked is keyed by kind and tag.
+	Tracked map[string]trackedTargetRecord `json:"tracked,omitempty"`
+	// ClanRosters and ClanEvents are keyed by clan tag.
+	ClanRosters map[string][]rosterMemberRecord `json:"clanRosters,omitempty"`
+	ClanEvents  map[string][]clanEventRecord    `json:"clanEvents,omitempty"`
+	// Webhooks is keyed by subscription ID; WebhookDeliveries by subscription ID,
+	// oldest first.
+	Webhooks          map[string]webhookRecord           `json:"webhooks,omitempty"`
+	WebhookDeliveries map[string][]webhookDeliveryRecord `json:"webhookDeliveries,omitempty"`
+	// APIKeys is keyed by key ID; APIKeyUsage by key ID, oldest day first.
+	APIKeys     map[string]apiKeyRecord        `json:"apiKeys,omitempty"`
+	APIKeyUsage map[string][]apiKeyUsageRecord `json:"apiKeyUsage,omitempty"`
+}
+
+// fileStoreFlushInterval bounds how long deferred writes stay in memory only.
+const fileStoreFlushInterval = 5 * time.Second
+
+// NewFileStore opens the store at path, starting empty when the file does not exist.
+// Call Close to stop the background flusher and write pending changes.
+func NewFileStore(path string) (*FileStore, error) {
+	s := &FileStore{path: path, stop: make(chan struct{}), done: make(chan struct{})}
+	b, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &s.data); err != nil {
+			return nil, err
+		}
+	}
+	if s.snapshots, err = openSnapshotFiles(snapshotDir(path)); err != nil {
+		return nil, err
+	}
+	if len(s.data.Snapshots) > 0 {
+		if err := s.snapshots.adopt(s.data.Snapshots); err != nil {
+			return nil, err
+		}
+		s.data.Snapshots = nil
+		if err := s.persist(); err != nil {
+			return nil, err
+		}
+	}
+	go s.flushLoop()
+	return s, nil
+}
+
+// Flush writes pending deferred changes to disk.
+func (s *FileStore) Flush() error {
+	snapshotErr := s.snapshots.flush()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.dirty {
+		if err := s.persist(); err != nil {
+			return err
+		}
+	}
+	return snapshotErr
+}
+
+// Close stops the background flusher and writes pending changes.
+func (s *FileStore) Close() error {
+	select {
+	case <-s.stop:
+	default:
+		close(s.stop)
+	}
+	<-s.done
+	return s.Flush()
+}
+
+func (s *FileStore) flushLoop() {
+	defer close(s.done)
+	t := time.NewTicker(fileStoreFlushInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-t.C:
+			if err := s.Flush(); err != nil {
+				slog.Warn("failed to flush store", "path", s.path, "error", err)
+			}
+		}
+	}
+}
+
+// Ping reports the error of the last failed write, if it has not been followed by a
+// successful one, and otherwise checks that the store's directory is still writable.
+func (s *FileStore) Ping(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.writeErr != nil {
+		return s.writeErr
+	}
+	if err := s.snapshots.err(); err != nil {
+		return err
+	}
+	dir := filepath.Dir(s.path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, filepath.Base(s.path)+".*.ping")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// markDirty defers writing the current document. Callers must hold s.mu.
+func (s *FileStore) markDirty() {
+	s.dirty = true
+}
+
+// persist writes the current document to disk. Callers must hold s.mu.
+func (s *FileStore) persist() error {
+	s.writeErr = s.write()
+	return s.writeErr
+}
+
+// persistOrRevert writes the document and, when that fails, runs reverts to undo the
+// caller's change, so that memory never gets ahead of the file: a change reported as
+// failed must not be served until the next restart either. Callers must hold s.mu.
+func (s *FileStore) persistOrRevert(reverts ...func()) error {
+	err := s.persist()
+	if err != nil {
+		for _, revert := range reverts {
+			revert()
+		}
+	}
+	return err
+}
+
+// entryReverter returns a function restoring m[key] to its current value, or to
+// being absent. m must not be nil if key is present.
+func entryReverter[K comparable, V any](m map[K]V, key K) func() {
+	old, had := m[key]
+	return func() {
+		if had {
+			m[key] = old
+		} else {
+			delete(m, key)
+		}
+	}
+}
+
+func (s *FileStore) write() error {
+	b, err := json.Marshal(s.data)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(s.path, b); err != nil {
+		return err
+	}
+	s.dirty = false
+	return nil
+}
+
+// writeFileAtomic replaces the file at path with b through a temporary file that is
+// synced before the rename, so a crash leaves either the old or the new content.
+func writeFileAtomic(path string, b []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
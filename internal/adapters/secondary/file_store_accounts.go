@@ -0,0 +1,59 @@
+package secondary
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
+)
+
+type accountLinkRecord struct {
+	UserID    string    `json:"userId"`
+	PlayerTag string    `json:"playerTag"`
+	LinkedAt  time.Time `json:"linkedAt"`
+}
+
+func (s *FileStore) ListAccountLinks(ctx context.Context, userID string) ([]models.AccountLink, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]models.AccountLink, 0)
+	for _, r := range s.data.AccountLinks {
+		if r.UserID == userID {
+			out = append(out, models.AccountLink{UserID: r.UserID, PlayerTag: r.PlayerTag, LinkedAt: r.LinkedAt})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].LinkedAt.Equal(out[j].LinkedAt) {
+			return out[i].LinkedAt.Before(out[j].LinkedAt)
+		}
+		return out[i].PlayerTag < out[j].PlayerTag
+	})
+	return out, nil
+}
+
+func (s *FileStore) SaveAccountLink(ctx context.Context, link models.AccountLink) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data.AccountLinks == nil {
+		s.data.AccountLinks = map[string]accountLinkRecord{}
+	}
+	revert := entryReverter(s.data.AccountLinks, link.PlayerTag)
+	s.data.AccountLinks[link.PlayerTag] = accountLinkRecord{UserID: link.UserID, PlayerTag: link.PlayerTag, LinkedAt: link.LinkedAt}
+	return s.persistOrRevert(revert)
+}
+
+func (s *FileStore) DeleteAccountLink(ctx context.Context, userID, playerTag string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.data.AccountLinks[playerTag]
+	if !ok || r.UserID != userID {
+		return false, nil
+	}
+	revert := entryReverter(s.data.AccountLinks, playerTag)
+	delete(s.data.AccountLinks, playerTag)
+	if err := s.persistOrRevert(revert); err != nil {
+		return false, err
+	}
+	return true, nil
+}
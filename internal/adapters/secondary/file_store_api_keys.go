@@ -0,0 +1,175 @@
+package secondary
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
+)
+
+// maxAPIKeyUsageDays bounds the daily usage kept per key.
+const maxAPIKeyUsageDays = 90
+
+type apiKeyRecord struct {
+	Name       string    `json:"name"`
+	SecretHash string    `json:"secretHash"`
+	Scopes     []string  `json:"scopes"`
+	UserID     string    `json:"userId,omitempty"`
+	RateLimit  float64   `json:"rateLimit,omitempty"`
+	RateBurst  int       `json:"rateBurst,omitempty"`
+	DailyQuota int       `json:"dailyQuota,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type apiKeyUsageRecord struct {
+	Day           string    `json:"day"`
+	Requests      int       `json:"requests"`
+	RateLimited   int       `json:"rateLimited,omitempty"`
+	QuotaExceeded int       `json:"quotaExceeded,omitempty"`
+	LastUsedAt    time.Time `json:"lastUsedAt"`
+}
+
+func (s *FileStore) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]models.APIKey, 0, len(s.data.APIKeys))
+	for id, r := range s.data.APIKeys {
+		out = append(out, r.toModel(id))
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.Before(out[j].CreatedAt)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
+
+func (s *FileStore) FindAPIKey(ctx context.Context, secretHash string) (models.APIKey, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, r := range s.data.APIKeys {
+		if r.SecretHash == secretHash {
+			return r.toModel(id), true, nil
+		}
+	}
+	return models.APIKey{}, false, nil
+}
+
+func (s *FileStore) SaveAPIKey(ctx context.Context, key models.APIKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data.APIKeys == nil {
+		s.data.APIKeys = map[string]apiKeyRecord{}
+	}
+	revert := entryReverter(s.data.APIKeys, key.ID)
+	s.data.APIKeys[key.ID] = apiKeyRecord{
+		Name:       key.Name,
+		SecretHash: key.SecretHash,
+		Scopes:     append([]string(nil), key.Scopes...),
+		UserID:     key.UserID,
+		RateLimit:  key.RateLimit,
+		RateBurst:  key.RateBurst,
+		DailyQuota: key.DailyQuota,
+		CreatedAt:  key.CreatedAt,
+	}
+	return s.persistOrRevert(revert)
+}
+
+func (s *FileStore) DeleteAPIKey(ctx context.Context, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.data.APIKeys[id]; !ok {
+		return false, nil
+	}
+	reverts := []func(){entryReverter(s.data.APIKeys, id), entryReverter(s.data.APIKeyUsage, id)}
+	delete(s.data.APIKeys, id)
+	delete(s.data.APIKeyUsage, id)
+	if err := s.persistOrRevert(reverts...); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *FileStore) GetAPIKeyUsage(ctx context.Context, keyID, day string) (models.APIKeyUsage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := s.data.APIKeyUsage[keyID]
+	if n := len(list); n > 0 && list[n-1].Day == day {
+		return list[n-1].toModel(keyID), nil
+	}
+	for _, r := range list {
+		if r.Day == day {
+			return r.toModel(keyID), nil
+		}
+	}
+	return models.APIKeyUsage{KeyID: keyID, Day: day}, nil
+}
+
+// SaveAPIKeyUsage is called on every request, so it only marks the store dirty.
+func (s *FileStore) SaveAPIKeyUsage(ctx context.Context, u models.APIKeyUsage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data.APIKeyUsage == nil {
+		s.data.APIKeyUsage = map[string][]apiKeyUsageRecord{}
+	}
+	rec := apiKeyUsageRecord{
+		Day:           u.Day,
+		Requests:      u.Requests,
+		RateLimited:   u.RateLimited,
+		QuotaExceeded: u.QuotaExceeded,
+		LastUsedAt:    u.LastUsedAt,
+	}
+	list := s.data.APIKeyUsage[u.KeyID]
+	i := sort.Search(len(list), func(i int) bool { return list[i].Day >= u.Day })
+	if i < len(list) && list[i].Day == u.Day {
+		list[i] = rec
+	} else {
+		list = append(list, apiKeyUsageRecord{})
+		copy(list[i+1:], list[i:])
+		list[i] = rec
+	}
+	if len(list) > maxAPIKeyUsageDays {
+		list = list[len(list)-maxAPIKeyUsageDays:]
+	}
+	s.data.APIKeyUsage[u.KeyID] = list
+	s.markDirty()
+	return nil
+}
+
+func (s *FileStore) ListAPIKeyUsage(ctx context.Context, keyID string) ([]models.APIKeyUsage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := s.data.APIKeyUsage[keyID]
+	out := make([]models.APIKeyUsage, len(list))
+	for i, r := range list {
+		out[i] = r.toModel(keyID)
+	}
+	return out, nil
+}
+
+func (r apiKeyRecord) toModel(id string) models.APIKey {
+	return models.APIKey{
+		ID:         id,
+		Name:       r.Name,
+		SecretHash: r.SecretHash,
+		Scopes:     append([]string(nil), r.Scopes...),
+		UserID:     r.UserID,
+		RateLimit:  r.RateLimit,
+		RateBurst:  r.RateBurst,
+		DailyQuota: r.DailyQuota,
+		CreatedAt:  r.CreatedAt,
+	}
+}
+
+func (r apiKeyUsageRecord) toModel(keyID string) models.APIKeyUsage {
+	return models.APIKeyUsage{
+		KeyID:         keyID,
+		Day:           r.Day,
+		Requests:      r.Requests,
+		RateLimited:   r.RateLimited,
+		QuotaExceeded: r.QuotaExceeded,
+		LastUsedAt:    r.LastUsedAt,
+	}
+}
@@ -0,0 +1,115 @@
+package secondary
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
+)
+
+// maxClanEventsPerClan bounds stored history; the oldest events are dropped first.
+const maxClanEventsPerClan = 5000
+
+type rosterMemberRecord struct {
+	Tag  string `json:"tag"`
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+type clanEventRecord struct {
+	ID         string               `json:"id"`
+	Type       models.ClanEventType `json:"type"`
+	OccurredAt time.Time            `json:"occurredAt"`
+	PlayerTag  string               `json:"playerTag"`
+	PlayerName string               `json:"playerName"`
+	OldValue   string               `json:"oldValue,omitempty"`
+	NewValue   string               `json:"newValue,omitempty"`
+}
+
+func (s *FileStore) GetClanRoster(ctx context.Context, clanTag string) ([]models.ClanRosterMember, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	recs, ok := s.data.ClanRosters[clanTag]
+	if !ok {
+		return nil, false, nil
+	}
+	out := make([]models.ClanRosterMember, len(recs))
+	for i, r := range recs {
+		out[i] = models.ClanRosterMember(r)
+	}
+	return out, true, nil
+}
+
+func (s *FileStore) SaveClanRoster(ctx context.Context, clanTag string, roster []models.ClanRosterMember, events []models.ClanEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data.ClanRosters == nil {
+		s.data.ClanRosters = map[string][]rosterMemberRecord{}
+	}
+	recs := make([]rosterMemberRecord, len(roster))
+	for i, m := range roster {
+		recs[i] = rosterMemberRecord(m)
+	}
+	if len(events) == 0 {
+		s.data.ClanRosters[clanTag] = recs
+		s.markDirty()
+		return nil
+	}
+	if s.data.ClanEvents == nil {
+		s.data.ClanEvents = map[string][]clanEventRecord{}
+	}
+	reverts := []func(){entryReverter(s.data.ClanRosters, clanTag)}
+	for _, e := range events {
+		reverts = append(reverts, entryReverter(s.data.ClanEvents, e.ClanTag))
+	}
+	s.data.ClanRosters[clanTag] = recs
+	for _, e := range events {
+		list := append(s.data.ClanEvents[e.ClanTag], clanEventRecord{
+			ID:         e.ID,
+			Type:       e.Type,
+			OccurredAt: e.OccurredAt,
+			PlayerTag:  e.PlayerTag,
+			PlayerName: e.PlayerName,
+			OldValue:   e.OldValue,
+			NewValue:   e.NewValue,
+		})
+		if len(list) > maxClanEventsPerClan {
+			list = list[len(list)-maxClanEventsPerClan:]
+		}
+		s.data.ClanEvents[e.ClanTag] = list
+	}
+	// Events are rare and not reproducible, so write them through together with the
+	// roster they were diffed against; a roster saved without its events would hide
+	// the changes on the next diff.
+	return s.persistOrRevert(reverts...)
+}
+
+func (s *FileStore) ListClanEvents(ctx context.Context, clanTag string, from, to time.Time, types []models.ClanEventType) ([]models.ClanEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]models.ClanEvent, 0)
+	for _, r := range s.data.ClanEvents[clanTag] {
+		if !from.IsZero() && r.OccurredAt.Before(from) {
+			continue
+		}
+		if !to.IsZero() && r.OccurredAt.After(to) {
+			continue
+		}
+		if len(types) > 0 && !slices.Contains(types, r.Type) {
+			continue
+		}
+		out = append(out, models.ClanEvent{
+			ID:         r.ID,
+			ClanTag:    clanTag,
+			Type:       r.Type,
+			OccurredAt: r.OccurredAt,
+			PlayerTag:  r.PlayerTag,
+			PlayerName: r.PlayerName,
+			OldValue:   r.OldValue,
+			NewValue:   r.NewValue,
+		})
+	}
+	slices.SortStableFunc(out, func(a, b models.ClanEvent) int { return a.OccurredAt.Compare(b.OccurredAt) })
+	return out, nil
+}
@@ -0,0 +1,63 @@
+package secondary
+
+import (
+	"context"
+	"sort"
+
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
+)
+
+type familyRecord struct {
+	Name     string   `json:"name"`
+	ClanTags []string `json:"clanTags"`
+}
+
+func (s *FileStore) ListFamilies(ctx context.Context) ([]models.ClanFamily, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]models.ClanFamily, 0, len(s.data.Families))
+	for _, r := range s.data.Families {
+		out = append(out, r.toModel())
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func (s *FileStore) GetFamily(ctx context.Context, name string) (models.ClanFamily, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.data.Families[name]
+	if !ok {
+		return models.ClanFamily{}, false, nil
+	}
+	return r.toModel(), true, nil
+}
+
+func (s *FileStore) SaveFamily(ctx context.Context, family models.ClanFamily) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data.Families == nil {
+		s.data.Families = map[string]familyRecord{}
+	}
+	revert := entryReverter(s.data.Families, family.Name)
+	s.data.Families[family.Name] = familyRecord{Name: family.Name, ClanTags: append([]string(nil), family.ClanTags...)}
+	return s.persistOrRevert(revert)
+}
+
+func (s *FileStore) DeleteFamily(ctx context.Context, name string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.data.Families[name]; !ok {
+		return false, nil
+	}
+	revert := entryReverter(s.data.Families, name)
+	delete(s.data.Families, name)
+	if err := s.persistOrRevert(revert); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r familyRecord) toModel() models.ClanFamily {
+	return models.ClanFamily{Name: r.Name, ClanTags: append([]string(nil), r.ClanTags...)}
+}
@@ -0,0 +1,251 @@
+package secondary
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
+)
+
+// snapshotFiles keeps player snapshot history out of the main document: each player's
+// list is a JSON file of its own in dir, written by the flusher only when it changed.
+// Recording a snapshot thus neither waits for the store's lock nor rewrites the
+// configuration and every other player's history.
+type snapshotFiles struct {
+	dir string
+
+	mu sync.Mutex
+	// players is keyed by player tag; each list is ordered by TakenAt.
+	players map[string][]snapshotRecord
+	dirty   map[string]bool
+	// writeErr is the error of the last failed flush, cleared by a successful one.
+	writeErr error
+}
+
+// snapshotDir is where the snapshots of the store at path live: data/store.json keeps
+// them in data/store.snapshots.
+func snapshotDir(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ".snapshots"
+}
+
+func openSnapshotFiles(dir string) (*snapshotFiles, error) {
+	f := &snapshotFiles{dir: dir, players: map[string][]snapshotRecord{}, dirty: map[string]bool{}}
+	entries, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok || e.IsDir() {
+			continue
+		}
+		tag, err := url.PathUnescape(name)
+		if err != nil {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var list []snapshotRecord
+		if err := json.Unmarshal(b, &list); err != nil {
+			return nil, err
+		}
+		f.players[tag] = list
+	}
+	return f, nil
+}
+
+// adopt takes over snapshot lists kept in the main document by earlier versions,
+// writing them to their own files right away. Players that already have a file keep it.
+func (f *snapshotFiles) adopt(legacy map[string][]snapshotRecord) error {
+	f.mu.Lock()
+	for tag, list := range legacy {
+		if _, ok := f.players[tag]; !ok {
+			f.players[tag] = list
+			f.dirty[tag] = true
+		}
+	}
+	f.mu.Unlock()
+	return f.flush()
+}
+
+// flush writes the lists of players changed since the last flush.
+func (f *snapshotFiles) flush() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var firstErr error
+	for tag := range f.dirty {
+		if err := f.writePlayer(tag); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		delete(f.dirty, tag)
+	}
+	f.writeErr = firstErr
+	return firstErr
+}
+
+// writePlayer writes one player's list, removing the file of a player without
+// snapshots. Callers must hold f.mu.
+func (f *snapshotFiles) writePlayer(tag string) error {
+	path := filepath.Join(f.dir, url.PathEscape(tag)+".json")
+	list := f.players[tag]
+	if len(list) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	b, err := json.Marshal(list)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, b)
+}
+
+func (f *snapshotFiles) err() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.writeErr
+}
+
+type snapshotRecord struct {
+	TakenAt    time.Time     `json:"takenAt"`
+	LastSeenAt time.Time     `json:"lastSeenAt"`
+	TownHall   int           `json:"townHall"`
+	Heroes     []levelRecord `json:"heroes,omitempty"`
+	Equipment  []levelRecord `json:"equipment,omitempty"`
+}
+
+type levelRecord struct {
+	Name     string `json:"name"`
+	Level    int    `json:"level"`
+	MaxLevel int    `json:"maxLevel,omitempty"`
+}
+
+func (s *FileStore) SaveSnapshot(ctx context.Context, snapshot models.PlayerSnapshot) (models.PlayerSnapshot, bool, bool, error) {
+	f := s.snapshots
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	list := f.players[snapshot.PlayerTag]
+	var prev models.PlayerSnapshot
+	hadPrev := len(list) > 0
+	if hadPrev {
+		latest := &list[len(list)-1]
+		prev = latest.toModel(snapshot.PlayerTag)
+		if prev.SameLevels(snapshot) {
+			if snapshot.TakenAt.After(latest.LastSeenAt) {
+				latest.LastSeenAt = snapshot.TakenAt
+				f.dirty[snapshot.PlayerTag] = true
+			}
+			return prev, true, false, nil
+		}
+	}
+	rec := snapshotFromModel(snapshot)
+	list = append(list, rec)
+	sort.SliceStable(list, func(i, j int) bool { return list[i].TakenAt.Before(list[j].TakenAt) })
+	f.players[snapshot.PlayerTag] = list
+	f.dirty[snapshot.PlayerTag] = true
+	return prev, hadPrev, true, nil
+}
+
+func (s *FileStore) ListSnapshots(ctx context.Context, playerTag string, from, to time.Time) ([]models.PlayerSnapshot, error) {
+	s.snapshots.mu.Lock()
+	defer s.snapshots.mu.Unlock()
+	list := s.snapshots.players[playerTag]
+	out := make([]models.PlayerSnapshot, 0, len(list))
+	baseline := -1
+	for i, r := range list {
+		if !from.IsZero() && r.TakenAt.Before(from) {
+			baseline = i
+			continue
+		}
+		if !to.IsZero() && r.TakenAt.After(to) {
+			break
+		}
+		if baseline >= 0 {
+			out = append(out, list[baseline].toModel(playerTag))
+			baseline = -1
+		}
+		out = append(out, r.toModel(playerTag))
+	}
+	if baseline >= 0 {
+		out = append(out, list[baseline].toModel(playerTag))
+	}
+	return out, nil
+}
+
+func (s *FileStore) LatestSnapshot(ctx context.Context, playerTag string) (models.PlayerSnapshot, bool, error) {
+	s.snapshots.mu.Lock()
+	defer s.snapshots.mu.Unlock()
+	list := s.snapshots.players[playerTag]
+	if len(list) == 0 {
+		return models.PlayerSnapshot{}, false, nil
+	}
+	return list[len(list)-1].toModel(playerTag), true, nil
+}
+
+func (s *FileStore) PruneSnapshots(ctx context.Context, playerTag string, cutoff time.Time, maxPerPlayer int) (int, error) {
+	f := s.snapshots
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	removed := 0
+	for tag, list := range f.players {
+		if playerTag != "" && tag != playerTag {
+			continue
+		}
+		kept := list[:0]
+		for i, r := range list {
+			latest := i == len(list)-1
+			if !latest && !cutoff.IsZero() && r.LastSeenAt.Before(cutoff) {
+				continue
+			}
+			kept = append(kept, r)
+		}
+		if maxPerPlayer > 0 && len(kept) > maxPerPlayer {
+			kept = kept[len(kept)-maxPerPlayer:]
+		}
+		if len(kept) < len(list) {
+			removed += len(list) - len(kept)
+			f.players[tag] = kept
+			f.dirty[tag] = true
+		}
+	}
+	return removed, nil
+}
+
+func snapshotFromModel(m models.PlayerSnapshot) snapshotRecord {
+	r := snapshotRecord{TakenAt: m.TakenAt, LastSeenAt: m.LastSeenAt, TownHall: m.TownHall}
+	if r.LastSeenAt.IsZero() {
+		r.LastSeenAt = r.TakenAt
+	}
+	for _, h := range m.Heroes {
+		r.Heroes = append(r.Heroes, levelRecord{Name: h.Name, Level: h.Level, MaxLevel: h.MaxLevel})
+	}
+	for _, e := range m.Equipment {
+		r.Equipment = append(r.Equipment, levelRecord{Name: e.Name, Level: e.Level, MaxLevel: e.MaxLevel})
+	}
+	return r
+}
+
+func (r snapshotRecord) toModel(playerTag string) models.PlayerSnapshot {
+	m := models.PlayerSnapshot{PlayerTag: playerTag, TakenAt: r.TakenAt, LastSeenAt: r.LastSeenAt, TownHall: r.TownHall}
+	for _, h := range r.Heroes {
+		m.Heroes = append(m.Heroes, models.HeroLevel{Name: h.Name, Level: h.Level, MaxLevel: h.MaxLevel})
+	}
+	for _, e := range r.Equipment {
+		m.Equipment = append(m.Equipment, models.EquipmentLevel{Name: e.Name, Level: e.Level, MaxLevel: e.MaxLevel})
+	}
+	return m
+}
@@ -0,0 +1,60 @@
+package secondary
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
+)
+
+// TestFailedWriteLeavesMemoryUnchanged makes the store's path a directory so every
+// write fails, and checks that the failed changes are not served afterwards.
+func TestFailedWriteLeavesMemoryUnchanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+	s, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	ctx := context.Background()
+	if err := s.SaveFamily(ctx, models.ClanFamily{Name: "main", ClanTags: []string{"#C1"}}); err != nil {
+		t.Fatalf("save family: %v", err)
+	}
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(path, "blocker"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.SaveFamily(ctx, models.ClanFamily{Name: "main", ClanTags: []string{"#C2"}}); err == nil {
+		t.Fatal("replacing a family: expected a write error")
+	}
+	if err := s.SaveFamily(ctx, models.ClanFamily{Name: "feeder", ClanTags: []string{"#C3"}}); err == nil {
+		t.Fatal("adding a family: expected a write error")
+	}
+	if ok, err := s.DeleteFamily(ctx, "main"); err == nil || ok {
+		t.Fatalf("deleting a family: ok %v, err %v, want a write error", ok, err)
+	}
+	families, _ := s.ListFamilies(ctx)
+	if len(families) != 1 || families[0].Name != "main" || families[0].ClanTags[0] != "#C1" {
+		t.Errorf("families after failed writes = %+v, want only main with #C1", families)
+	}
+
+	if err := s.SaveClanRoster(ctx, "#C1", []models.ClanRosterMember{{Tag: "#P1"}}, []models.ClanEvent{{ClanTag: "#C1", Type: models.ClanMemberJoined, PlayerTag: "#P1"}}); err == nil {
+		t.Fatal("saving a roster with events: expected a write error")
+	}
+	if _, ok, _ := s.GetClanRoster(ctx, "#C1"); ok {
+		t.Error("roster of a failed write is served")
+	}
+	if events, _ := s.ListClanEvents(ctx, "#C1", time.Time{}, time.Time{}, nil); len(events) != 0 {
+		t.Errorf("events of a failed write are served: %+v", events)
+	}
+
+	os.RemoveAll(path)
+	if err := s.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package secondary
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
+)
+
+type trackedTargetRecord struct {
+	Kind      models.TrackedKind `json:"kind"`
+	Tag       string             `json:"tag"`
+	Interval  string             `json:"interval"`
+	CreatedAt time.Time          `json:"createdAt"`
+	Status    trackerRunRecord   `json:"status"`
+}
+
+type trackerRunRecord struct {
+	LastRunAt         time.Time `json:"lastRunAt,omitzero"`
+	LastSuccessAt     time.Time `json:"lastSuccessAt,omitzero"`
+	LastError         string    `json:"lastError,omitempty"`
+	LastFailedMembers int       `json:"lastFailedMembers,omitempty"`
+	Runs              int       `json:"runs,omitempty"`
+	Failures          int       `json:"failures,omitempty"`
+}
+
+func trackedKey(kind models.TrackedKind, tag string) string { return string(kind) + ":" + tag }
+
+func (s *FileStore) ListTrackedTargets(ctx context.Context) ([]models.TrackedTarget, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]models.TrackedTarget, 0, len(s.data.Tracked))
+	for _, r := range s.data.Tracked {
+		out = append(out, r.toModel())
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Kind != out[j].Kind {
+			return out[i].Kind < out[j].Kind
+		}
+		return out[i].Tag < out[j].Tag
+	})
+	return out, nil
+}
+
+func (s *FileStore) SaveTrackedTarget(ctx context.Context, target models.TrackedTarget) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data.Tracked == nil {
+		s.data.Tracked = map[string]trackedTargetRecord{}
+	}
+	key := trackedKey(target.Kind, target.Tag)
+	rec := trackedTargetRecord{
+		Kind:      target.Kind,
+		Tag:       target.Tag,
+		Interval:  target.Interval.String(),
+		CreatedAt: target.CreatedAt,
+	}
+	if prev, ok := s.data.Tracked[key]; ok {
+		rec.CreatedAt = prev.CreatedAt
+		rec.Status = prev.Status
+	}
+	revert := entryReverter(s.data.Tracked, key)
+	s.data.Tracked[key] = rec
+	return s.persistOrRevert(revert)
+}
+
+func (s *FileStore) DeleteTrackedTarget(ctx context.Context, kind models.TrackedKind, tag string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := trackedKey(kind, tag)
+	if _, ok := s.data.Tracked[key]; !ok {
+		return false, nil
+	}
+	revert := entryReverter(s.data.Tracked, key)
+	delete(s.data.Tracked, key)
+	if err := s.persistOrRevert(revert); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *FileStore) RecordTrackerRun(ctx context.Context, kind models.TrackedKind, tag string, run models.TrackerRun) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := trackedKey(kind, tag)
+	rec, ok := s.data.Tracked[key]
+	if !ok {
+		return nil
+	}
+	rec.Status = trackerRunRecord(run)
+	s.data.Tracked[key] = rec
+	s.markDirty()
+	return nil
+}
+
+func (r trackedTargetRecord) toModel() models.TrackedTarget {
+	interval, _ := time.ParseDuration(r.Interval)
+	return models.TrackedTarget{
+		Kind:      r.Kind,
+		Tag:       r.Tag,
+		Interval:  interval,
+		CreatedAt: r.CreatedAt,
+		Status:    models.TrackerRun(r.Status),
+	}
+}
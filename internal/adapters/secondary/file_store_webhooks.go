@@ -0,0 +1,151 @@
+package secondary
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
+)
+
+// maxWebhookDeliveries bounds the delivery log kept per subscription.
+const maxWebhookDeliveries = 200
+
+type webhookRecord struct {
+	URL        string             `json:"url"`
+	Secret     string             `json:"secret"`
+	EventTypes []models.EventType `json:"eventTypes"`
+	CreatedAt  time.Time          `json:"createdAt"`
+}
+
+type webhookDeliveryRecord struct {
+	ID          string           `json:"id"`
+	EventID     string           `json:"eventId"`
+	EventType   models.EventType `json:"eventType"`
+	Attempt     int              `json:"attempt"`
+	AttemptedAt time.Time        `json:"attemptedAt"`
+	Duration    string           `json:"duration"`
+	StatusCode  int              `json:"statusCode,omitempty"`
+	Error       string           `json:"error,omitempty"`
+	Success     bool             `json:"success"`
+}
+
+func (s *FileStore) ListWebhooks(ctx context.Context) ([]models.WebhookSubscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]models.WebhookSubscription, 0, len(s.data.Webhooks))
+	for id, r := range s.data.Webhooks {
+		out = append(out, r.toModel(id))
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.Before(out[j].CreatedAt)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
+
+func (s *FileStore) GetWebhook(ctx context.Context, id string) (models.WebhookSubscription, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.data.Webhooks[id]
+	if !ok {
+		return models.WebhookSubscription{}, false, nil
+	}
+	return r.toModel(id), true, nil
+}
+
+func (s *FileStore) SaveWebhook(ctx context.Context, sub models.WebhookSubscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data.Webhooks == nil {
+		s.data.Webhooks = map[string]webhookRecord{}
+	}
+	revert := entryReverter(s.data.Webhooks, sub.ID)
+	s.data.Webhooks[sub.ID] = webhookRecord{
+		URL:        sub.URL,
+		Secret:     sub.Secret,
+		EventTypes: append([]models.EventType(nil), sub.EventTypes...),
+		CreatedAt:  sub.CreatedAt,
+	}
+	return s.persistOrRevert(revert)
+}
+
+func (s *FileStore) DeleteWebhook(ctx context.Context, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.data.Webhooks[id]; !ok {
+		return false, nil
+	}
+	reverts := []func(){entryReverter(s.data.Webhooks, id), entryReverter(s.data.WebhookDeliveries, id)}
+	delete(s.data.Webhooks, id)
+	delete(s.data.WebhookDeliveries, id)
+	if err := s.persistOrRevert(reverts...); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *FileStore) AppendWebhookDelivery(ctx context.Context, d models.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.data.Webhooks[d.SubscriptionID]; !ok {
+		// The subscription was deleted while the delivery was in flight.
+		return nil
+	}
+	if s.data.WebhookDeliveries == nil {
+		s.data.WebhookDeliveries = map[string][]webhookDeliveryRecord{}
+	}
+	list := append(s.data.WebhookDeliveries[d.SubscriptionID], webhookDeliveryRecord{
+		ID:          d.ID,
+		EventID:     d.EventID,
+		EventType:   d.EventType,
+		Attempt:     d.Attempt,
+		AttemptedAt: d.AttemptedAt,
+		Duration:    d.Duration.String(),
+		StatusCode:  d.StatusCode,
+		Error:       d.Error,
+		Success:     d.Success,
+	})
+	if len(list) > maxWebhookDeliveries {
+		list = list[len(list)-maxWebhookDeliveries:]
+	}
+	s.data.WebhookDeliveries[d.SubscriptionID] = list
+	s.markDirty()
+	return nil
+}
+
+func (s *FileStore) ListWebhookDeliveries(ctx context.Context, subscriptionID string, limit int) ([]models.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := s.data.WebhookDeliveries[subscriptionID]
+	out := make([]models.WebhookDelivery, 0, len(list))
+	for i := len(list) - 1; i >= 0 && (limit <= 0 || len(out) < limit); i-- {
+		r := list[i]
+		d, _ := time.ParseDuration(r.Duration)
+		out = append(out, models.WebhookDelivery{
+			ID:             r.ID,
+			SubscriptionID: subscriptionID,
+			EventID:        r.EventID,
+			EventType:      r.EventType,
+			Attempt:        r.Attempt,
+			AttemptedAt:    r.AttemptedAt,
+			Duration:       d,
+			StatusCode:     r.StatusCode,
+			Error:          r.Error,
+			Success:        r.Success,
+		})
+	}
+	return out, nil
+}
+
+func (r webhookRecord) toModel(id string) models.WebhookSubscription {
+	return models.WebhookSubscription{
+		ID:         id,
+		URL:        r.URL,
+		Secret:     r.Secret,
+		EventTypes: append([]models.EventType(nil), r.EventTypes...),
+		CreatedAt:  r.CreatedAt,
+	}
+}
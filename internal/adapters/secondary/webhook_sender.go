@@ -0,0 +1,57 @@
+package secondary
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/ab-dauletkhan/coc/internal/netguard"
+)
+
+// HTTPWebhookSender posts webhook payloads over HTTP. It implements ports.WebhookSender.
+type HTTPWebhookSender struct {
+	client *http.Client
+}
+
+// NewHTTPWebhookSender returns a sender whose requests time out after timeout. Unless
+// allowPrivate is set, connections to loopback, private, and link-local addresses are
+// refused when dialing, after name resolution, so subscriptions cannot reach the
+// internal network.
+func NewHTTPWebhookSender(timeout time.Duration, allowPrivate bool) *HTTPWebhookSender {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = netguard.Control
+	}
+	return &HTTPWebhookSender{client: &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			// No proxy: the guard must see the receiver's address, not the proxy's.
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConnsPerHost: 2,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		// Receivers must answer themselves; following redirects would resend signed payloads elsewhere.
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}}
+}
+
+func (s *HTTPWebhookSender) Send(ctx context.Context, url string, headers map[string]string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return resp.StatusCode, nil
+}
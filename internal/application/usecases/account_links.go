@@ -0,0 +1,187 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
+	"github.com/ab-dauletkhan/coc/internal/domain/ports"
+)
+
+// AccountLinkUseCase links player accounts to users of this API after verifying the
+// in-game API token, and reports equipment data across a user's linked accounts.
+type AccountLinkUseCase struct {
+	links     ports.AccountLinkRepository
+	verifier  ports.PlayerVerificationAPI
+	playerAPI ports.PlayerAPI
+	catalog   ports.CatalogRepository
+	fanOut    *FanOut
+	now       func() time.Time
+}
+
+func NewAccountLinkUseCase(links ports.AccountLinkRepository, verifier ports.PlayerVerificationAPI, playerAPI ports.PlayerAPI, catalog ports.CatalogRepository, fanOut *FanOut) *AccountLinkUseCase {
+	return &AccountLinkUseCase{links: links, verifier: verifier, playerAPI: playerAPI, catalog: catalog, fanOut: fanOut, now: time.Now}
+}
+
+var (
+	// ErrInvalidPlayerToken is returned (with status 403) when the upstream API rejects the token.
+	ErrInvalidPlayerToken = errors.New("player token is invalid")
+	// ErrAccountNotLinked is returned (with status 404) when unlinking a tag the user does not own.
+	ErrAccountNotLinked = errors.New("account is not linked")
+)
+
+type AccountLink struct {
+	PlayerTag string    `json:"playerTag"`
+	LinkedAt  time.Time `json:"linkedAt"`
+}
+
+type LinkedAccount struct {
+	PlayerTag string           `json:"playerTag"`
+	Name      string           `json:"name"`
+	TownHall  int              `json:"townHall"`
+	LinkedAt  time.Time        `json:"linkedAt"`
+	Status    MemberStatus     `json:"status"`
+	Spent     models.OreTotals `json:"spent"`
+	Equipment []AccountItem    `json:"equipment"`
+}
+
+type AccountItem struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Level    int    `json:"level"`
+	MaxLevel int    `json:"maxLevel"`
+}
+
+type LinkedAccountsResult struct {
+	UserID         string           `json:"userId"`
+	Total          models.OreTotals `json:"total"`
+	Complete       bool             `json:"complete"`
+	FailedAccounts int              `json:"failedAccounts"`
+	Accounts       []LinkedAccount  `json:"accounts"`
+}
+
+// Link verifies that the caller owns playerTag using the one-time token from the game
+// settings and links it to userID. The token is not stored.
+func (uc *AccountLinkUseCase) Link(ctx context.Context, userID, playerTag, token string) (AccountLink, int, error) {
+	ctx, span := startSpan(ctx, "AccountLinkUseCase.Link", attribute.String("coc.player_tag", playerTag))
+	res, status, err := uc.link(ctx, userID, playerTag, token)
+	endSpan(span, status, err)
+	return res, status, err
+}
+
+func (uc *AccountLinkUseCase) link(ctx context.Context, userID, playerTag, token string) (AccountLink, int, error) {
+	var out AccountLink
+	tag := canonicalTag(playerTag)
+	if tag == "" || strings.TrimSpace(token) == "" {
+		return out, 400, fmt.Errorf("%w: playerTag and token are required", ErrInvalidQuery)
+	}
+	b, status, err := uc.verifier.VerifyPlayerTokenRaw(ctx, normalizePlayerTag(tag), strings.TrimSpace(token))
+	if err != nil || status >= 400 {
+		return out, status, err
+	}
+	var resp struct {
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(b, &resp); err != nil {
+		return out, 502, err
+	}
+	if resp.Status != "ok" {
+		return out, 403, ErrInvalidPlayerToken
+	}
+	link := models.AccountLink{UserID: userID, PlayerTag: tag, LinkedAt: uc.now().UTC()}
+	if err := uc.links.SaveAccountLink(ctx, link); err != nil {
+		return out, 500, err
+	}
+	return AccountLink{PlayerTag: link.PlayerTag, LinkedAt: link.LinkedAt}, 201, nil
+}
+
+func (uc *AccountLinkUseCase) Unlink(ctx context.Context, userID, playerTag string) (int, error) {
+	ok, err := uc.links.DeleteAccountLink(ctx, userID, canonicalTag(playerTag))
+	if err != nil {
+		return 500, err
+	}
+	if !ok {
+		return 404, ErrAccountNotLinked
+	}
+	return 204, nil
+}
+
+// Accounts fetches every linked account and returns per-account equipment and ore
+// spent together with totals across accounts that could be fetched.
+func (uc *AccountLinkUseCase) Accounts(ctx context.Context, userID string) (LinkedAccountsResult, int, error) {
+	ctx, span := startSpan(ctx, "AccountLinkUseCase.Accounts", attribute.String("app.user_id", userID))
+	res, status, err := uc.accounts(ctx, userID)
+	endSpan(span, status, err)
+	return res, status, err
+}
+
+func (uc *AccountLinkUseCase) accounts(ctx context.Context, userID string) (LinkedAccountsResult, int, error) {
+	out := LinkedAccountsResult{UserID: userID}
+	links, err := uc.links.ListAccountLinks(ctx, userID)
+	if err != nil {
+		return out, 500, err
+	}
+	members := make([]clanMember, len(links))
+	for i, l := range links {
+		members[i] = clanMember{Tag: l.PlayerTag}
+	}
+	fetched := uc.fanOut.fetchMemberPlayers(ctx, uc.playerAPI, members)
+
+	out.Accounts = make([]LinkedAccount, len(fetched))
+	for i, f := range fetched {
+		acc := LinkedAccount{PlayerTag: links[i].PlayerTag, LinkedAt: links[i].LinkedAt, Status: f.Status, Equipment: []AccountItem{}}
+		if f.Status == MemberStatusOK {
+			if err := uc.fillAccount(&acc, f.Body); err != nil {
+				acc.Status = MemberStatusError
+			}
+		}
+		if acc.Status != MemberStatusOK {
+			out.FailedAccounts++
+		} else {
+			out.Total.Shiny += acc.Spent.Shiny
+			out.Total.Glowy += acc.Spent.Glowy
+			out.Total.Starry += acc.Spent.Starry
+		}
+		out.Accounts[i] = acc
+	}
+	out.Complete = out.FailedAccounts == 0
+	return out, 200, nil
+}
+
+func (uc *AccountLinkUseCase) fillAccount(acc *LinkedAccount, body []byte) error {
+	var p struct {
+		Name          string `json:"name"`
+		TownHallLevel int    `json:"townHallLevel"`
+	}
+	if err := json.Unmarshal(body, &p); err != nil {
+		return err
+	}
+	levels, err := parseHeroEquipment(body)
+	if err != nil {
+		return err
+	}
+	spent, err := computePlayerOre(uc.catalog, body)
+	if err != nil {
+		return err
+	}
+	acc.Name = p.Name
+	acc.TownHall = p.TownHallLevel
+	acc.Spent = spent
+	for _, l := range levels {
+		acc.Equipment = append(acc.Equipment, AccountItem{ID: uc.catalog.GetID(l.Name), Name: l.Name, Level: l.Level, MaxLevel: l.MaxLevel})
+	}
+	sort.Slice(acc.Equipment, func(i, j int) bool {
+		if acc.Equipment[i].ID != acc.Equipment[j].ID {
+			return acc.Equipment[i].ID < acc.Equipment[j].ID
+		}
+		return acc.Equipment[i].Name < acc.Equipment[j].Name
+	})
+	return nil
+}
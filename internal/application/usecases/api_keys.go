@@ -0,0 +1,347 @@
+package usecases
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
+	"github.com/ab-dauletkhan/coc/internal/domain/ports"
+	"github.com/ab-dauletkhan/coc/internal/ratelimit"
+)
+
+// APIKeySettings holds the keys defined in the configuration and the limits applied
+// to keys that do not set their own. Zero limits disable them.
+type APIKeySettings struct {
+	Static     []models.APIKey
+	RateLimit  float64 // requests per second
+	RateBurst  int
+	DailyQuota int
+}
+
+// APIKeyUseCase authenticates API keys, enforces their rate limits and daily quotas,
+// and manages the keys kept in the store.
+type APIKeyUseCase struct {
+	keys     ports.APIKeyRepository
+	settings APIKeySettings
+	now      func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*ratelimit.Bucket
+	// usageMu serializes the read-modify-write of daily usage.
+	usageMu sync.Mutex
+}
+
+func NewAPIKeyUseCase(keys ports.APIKeyRepository, settings APIKeySettings) *APIKeyUseCase {
+	return &APIKeyUseCase{keys: keys, settings: settings, now: time.Now, buckets: map[string]*ratelimit.Bucket{}}
+}
+
+var (
+	// ErrAPIKeyNotFound is returned (with status 404) for an unknown key ID.
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	// ErrStaticAPIKey is returned (with status 409) when changing a configured key.
+	ErrStaticAPIKey = errors.New("api key is defined in the configuration")
+	// ErrRateLimited and ErrQuotaExceeded are returned (with status 429) by Admit.
+	ErrRateLimited   = errors.New("rate limit exceeded")
+	ErrQuotaExceeded = errors.New("daily quota exceeded")
+)
+
+// apiKeyPrefix marks generated secrets so they are recognizable in logs and scanners.
+const apiKeyPrefix = "coc_"
+
+// HashAPIKeySecret returns the hash under which a key's secret is stored.
+func HashAPIKeySecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+type APIKey struct {
+	ID     string   `json:"id"`
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+	UserID string   `json:"userId"`
+	// Limits are the effective values; 0 means unlimited.
+	RateLimit  float64 `json:"rateLimit"`
+	RateBurst  int     `json:"rateBurst"`
+	DailyQuota int     `json:"dailyQuota"`
+	Static     bool    `json:"static"`
+	// Secret is only returned when the key is created.
+	Secret    string     `json:"secret,omitempty"`
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+}
+
+type APIKeyUsageDay struct {
+	Day           string    `json:"day"`
+	Requests      int       `json:"requests"`
+	RateLimited   int       `json:"rateLimited"`
+	QuotaExceeded int       `json:"quotaExceeded"`
+	LastUsedAt    time.Time `json:"lastUsedAt"`
+}
+
+type APIKeyUsageResult struct {
+	KeyID string `json:"keyId"`
+	Name  string `json:"name"`
+	// Today's counters; Remaining is omitted when the key has no quota.
+	DailyQuota int              `json:"dailyQuota"`
+	Used       int              `json:"used"`
+	Remaining  *int             `json:"remaining,omitempty"`
+	Days       []APIKeyUsageDay `json:"days"`
+}
+
+// CreateAPIKeyInput describes a key to create; zero limits select the defaults.
+type CreateAPIKeyInput struct {
+	Name       string
+	Scopes     []string
+	UserID     string
+	RateLimit  float64
+	RateBurst  int
+	DailyQuota int
+}
+
+// Admission is the outcome of Admit. RetryAfter is set when the request is rejected.
+type Admission struct {
+	RetryAfter time.Duration
+	// Quota and Remaining describe today's quota; Quota is 0 when there is none.
+	Quota     int
+	Remaining int
+}
+
+// Authenticate returns the key with the given secret; ok is false when there is none.
+func (uc *APIKeyUseCase) Authenticate(ctx context.Context, secret string) (models.APIKey, bool, error) {
+	if secret == "" {
+		return models.APIKey{}, false, nil
+	}
+	hash := HashAPIKeySecret(secret)
+	for _, k := range uc.settings.Static {
+		if k.SecretHash == hash {
+			return k, true, nil
+		}
+	}
+	return uc.keys.FindAPIKey(ctx, hash)
+}
+
+// Admit counts a request made with key against its rate limit and daily quota.
+// Rejected requests are counted too but do not use up the quota.
+func (uc *APIKeyUseCase) Admit(ctx context.Context, key models.APIKey) (Admission, int, error) {
+	rate, burst, quota := uc.limits(key)
+	now := uc.now()
+
+	uc.usageMu.Lock()
+	defer uc.usageMu.Unlock()
+	usage, err := uc.keys.GetAPIKeyUsage(ctx, key.ID, usageDay(now))
+	if err != nil {
+		return Admission{}, 500, err
+	}
+	adm := Admission{Quota: quota}
+	var status int
+	switch {
+	case quota > 0 && usage.Requests >= quota:
+		usage.QuotaExceeded++
+		adm.RetryAfter = nextUsageDay(now).Sub(now)
+		status, err = 429, ErrQuotaExceeded
+	default:
+		if d := uc.bucket(key.ID, rate, burst).Take(now, 1); !d.Allowed {
+			usage.RateLimited++
+			adm.RetryAfter = d.RetryAfter
+			status, err = 429, ErrRateLimited
+			break
+		}
+		usage.Requests++
+		status = 200
+	}
+	if quota > 0 {
+		adm.Remaining = max(quota-usage.Requests, 0)
+	}
+	usage.LastUsedAt = now.UTC()
+	if serr := uc.keys.SaveAPIKeyUsage(ctx, usage); serr != nil {
+		return Admission{}, 500, serr
+	}
+	return adm, status, err
+}
+
+func (uc *APIKeyUseCase) limits(key models.APIKey) (rate float64, burst, quota int) {
+	rate, burst, quota = uc.settings.RateLimit, uc.settings.RateBurst, uc.settings.DailyQuota
+	if key.RateLimit > 0 {
+		rate = key.RateLimit
+	}
+	if key.RateBurst > 0 {
+		burst = key.RateBurst
+	}
+	if key.DailyQuota > 0 {
+		quota = key.DailyQuota
+	}
+	return rate, burst, quota
+}
+
+func (uc *APIKeyUseCase) bucket(id string, rate float64, burst int) *ratelimit.Bucket {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	b, ok := uc.buckets[id]
+	if !ok || !b.Matches(rate, burst) {
+		b = ratelimit.NewBucket(rate, burst, uc.now())
+		uc.buckets[id] = b
+	}
+	return b
+}
+
+// Create stores a new key and returns it with its secret, which is not kept.
+func (uc *APIKeyUseCase) Create(ctx context.Context, in CreateAPIKeyInput) (APIKey, int, error) {
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		return APIKey{}, 400, fmt.Errorf("%w: name is required", ErrInvalidQuery)
+	}
+	if len(in.Scopes) == 0 {
+		return APIKey{}, 400, fmt.Errorf("%w: at least one scope is required", ErrInvalidQuery)
+	}
+	var scopes []string
+	for _, s := range in.Scopes {
+		s = strings.TrimSpace(s)
+		if !slices.Contains(models.Scopes, s) {
+			return APIKey{}, 400, fmt.Errorf("%w: unknown scope %q", ErrInvalidQuery, s)
+		}
+		if !slices.Contains(scopes, s) {
+			scopes = append(scopes, s)
+		}
+	}
+	if in.RateLimit < 0 || in.RateBurst < 0 || in.DailyQuota < 0 {
+		return APIKey{}, 400, fmt.Errorf("%w: limits must not be negative", ErrInvalidQuery)
+	}
+	secret := apiKeyPrefix + newID() + newID()
+	key := models.APIKey{
+		ID:         newID(),
+		Name:       name,
+		SecretHash: HashAPIKeySecret(secret),
+		Scopes:     scopes,
+		UserID:     strings.TrimSpace(in.UserID),
+		RateLimit:  in.RateLimit,
+		RateBurst:  in.RateBurst,
+		DailyQuota: in.DailyQuota,
+		CreatedAt:  uc.now().UTC(),
+	}
+	if key.UserID == "" {
+		key.UserID = key.ID
+	}
+	if err := uc.keys.SaveAPIKey(ctx, key); err != nil {
+		return APIKey{}, 500, err
+	}
+	out := uc.toAPIKey(key)
+	out.Secret = secret
+	return out, 201, nil
+}
+
+// List returns the configured keys followed by the stored ones.
+func (uc *APIKeyUseCase) List(ctx context.Context) ([]APIKey, int, error) {
+	stored, err := uc.keys.ListAPIKeys(ctx)
+	if err != nil {
+		return nil, 500, err
+	}
+	out := make([]APIKey, 0, len(uc.settings.Static)+len(stored))
+	for _, k := range uc.settings.Static {
+		out = append(out, uc.toAPIKey(k))
+	}
+	for _, k := range stored {
+		out = append(out, uc.toAPIKey(k))
+	}
+	return out, 200, nil
+}
+
+func (uc *APIKeyUseCase) Delete(ctx context.Context, id string) (int, error) {
+	if _, ok := uc.static(id); ok {
+		return 409, ErrStaticAPIKey
+	}
+	ok, err := uc.keys.DeleteAPIKey(ctx, id)
+	if err != nil {
+		return 500, err
+	}
+	if !ok {
+		return 404, ErrAPIKeyNotFound
+	}
+	uc.mu.Lock()
+	delete(uc.buckets, id)
+	uc.mu.Unlock()
+	return 204, nil
+}
+
+// Usage returns the daily usage of a key, oldest day first.
+func (uc *APIKeyUseCase) Usage(ctx context.Context, id string) (APIKeyUsageResult, int, error) {
+	key, ok := uc.static(id)
+	if !ok {
+		keys, err := uc.keys.ListAPIKeys(ctx)
+		if err != nil {
+			return APIKeyUsageResult{}, 500, err
+		}
+		i := slices.IndexFunc(keys, func(k models.APIKey) bool { return k.ID == id })
+		if i < 0 {
+			return APIKeyUsageResult{}, 404, ErrAPIKeyNotFound
+		}
+		key = keys[i]
+	}
+	days, err := uc.keys.ListAPIKeyUsage(ctx, id)
+	if err != nil {
+		return APIKeyUsageResult{}, 500, err
+	}
+	_, _, quota := uc.limits(key)
+	out := APIKeyUsageResult{KeyID: key.ID, Name: key.Name, DailyQuota: quota, Days: make([]APIKeyUsageDay, len(days))}
+	today := usageDay(uc.now())
+	for i, d := range days {
+		out.Days[i] = APIKeyUsageDay{
+			Day:           d.Day,
+			Requests:      d.Requests,
+			RateLimited:   d.RateLimited,
+			QuotaExceeded: d.QuotaExceeded,
+			LastUsedAt:    d.LastUsedAt,
+		}
+		if d.Day == today {
+			out.Used = d.Requests
+		}
+	}
+	if quota > 0 {
+		remaining := max(quota-out.Used, 0)
+		out.Remaining = &remaining
+	}
+	return out, 200, nil
+}
+
+func (uc *APIKeyUseCase) static(id string) (models.APIKey, bool) {
+	for _, k := range uc.settings.Static {
+		if k.ID == id {
+			return k, true
+		}
+	}
+	return models.APIKey{}, false
+}
+
+func (uc *APIKeyUseCase) toAPIKey(k models.APIKey) APIKey {
+	rate, burst, quota := uc.limits(k)
+	out := APIKey{
+		ID:         k.ID,
+		Name:       k.Name,
+		Scopes:     append([]string(nil), k.Scopes...),
+		UserID:     k.UserID,
+		RateLimit:  rate,
+		RateBurst:  burst,
+		DailyQuota: quota,
+		Static:     k.Static,
+	}
+	if !k.CreatedAt.IsZero() {
+		t := k.CreatedAt
+		out.CreatedAt = &t
+	}
+	return out
+}
+
+// usageDay returns the UTC day t counts towards; quotas reset at midnight UTC.
+func usageDay(t time.Time) string {
+	return t.UTC().Format(time.DateOnly)
+}
+
+func nextUsageDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,96 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	secondary "github.com/ab-dauletkhan/coc/internal/adapters/secondary"
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
+)
+
+func TestAdmitQuotaRollsOverAtUTCMidnight(t *testing.T) {
+	store, err := secondary.NewFileStore(filepath.Join(t.TempDir(), "store.json"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer store.Close()
+	uc := NewAPIKeyUseCase(store, APIKeySettings{DailyQuota: 2})
+	key := models.APIKey{ID: "k"}
+	// UTC+5, so local midnight and UTC midnight differ.
+	tz := time.FixedZone("UTC+5", 5*60*60)
+
+	steps := []struct {
+		name       string
+		at         time.Time
+		wantErr    error
+		retryAfter time.Duration
+		remaining  int
+	}{
+		{"first", time.Date(2024, 3, 1, 23, 0, 0, 0, time.UTC), nil, 0, 1},
+		{"local midnight is not a new day", time.Date(2024, 3, 2, 0, 30, 0, 0, tz), nil, 0, 0},
+		{"quota used up", time.Date(2024, 3, 1, 23, 59, 0, 0, time.UTC), ErrQuotaExceeded, time.Minute, 0},
+		{"retry after counts to UTC midnight", time.Date(2024, 3, 2, 4, 59, 59, 500e6, tz), ErrQuotaExceeded, 500 * time.Millisecond, 0},
+		{"UTC midnight starts a new day", time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), nil, 0, 1},
+		{"new day in another zone", time.Date(2024, 3, 2, 5, 0, 1, 0, tz), nil, 0, 0},
+		{"new day used up", time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC), ErrQuotaExceeded, 12 * time.Hour, 0},
+	}
+	for _, s := range steps {
+		uc.now = func() time.Time { return s.at }
+		adm, status, err := uc.Admit(context.Background(), key)
+		if !errors.Is(err, s.wantErr) {
+			t.Fatalf("%s: err = %v, want %v", s.name, err, s.wantErr)
+		}
+		if wantStatus := map[bool]int{true: 200, false: 429}[s.wantErr == nil]; status != wantStatus {
+			t.Errorf("%s: status = %d, want %d", s.name, status, wantStatus)
+		}
+		if adm.RetryAfter != s.retryAfter {
+			t.Errorf("%s: RetryAfter = %s, want %s", s.name, adm.RetryAfter, s.retryAfter)
+		}
+		if adm.Quota != 2 || adm.Remaining != s.remaining {
+			t.Errorf("%s: quota %d remaining %d, want 2 and %d", s.name, adm.Quota, adm.Remaining, s.remaining)
+		}
+	}
+
+	usage, err := store.ListAPIKeyUsage(context.Background(), "k")
+	if err != nil || len(usage) != 2 {
+		t.Fatalf("usage = %+v, %v; want two days", usage, err)
+	}
+	for _, u := range usage {
+		if u.Requests != 2 || u.QuotaExceeded == 0 {
+			t.Errorf("usage of %s = %+v, want 2 requests and refusals", u.Day, u)
+		}
+	}
+}
+
+func TestAdmitRateLimitRetryAfter(t *testing.T) {
+	store, err := secondary.NewFileStore(filepath.Join(t.TempDir(), "store.json"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer store.Close()
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	uc := NewAPIKeyUseCase(store, APIKeySettings{RateLimit: 2, RateBurst: 2})
+	key := models.APIKey{ID: "k"}
+
+	steps := []struct {
+		after      time.Duration
+		wantErr    error
+		retryAfter time.Duration
+	}{
+		{0, nil, 0},
+		{0, nil, 0},
+		{0, ErrRateLimited, 500 * time.Millisecond},
+		{250 * time.Millisecond, ErrRateLimited, 250 * time.Millisecond},
+		{500 * time.Millisecond, nil, 0},
+	}
+	for i, s := range steps {
+		uc.now = func() time.Time { return start.Add(s.after) }
+		adm, _, err := uc.Admit(context.Background(), key)
+		if !errors.Is(err, s.wantErr) || adm.RetryAfter != s.retryAfter {
+			t.Errorf("step %d: err %v RetryAfter %s, want %v and %s", i, err, adm.RetryAfter, s.wantErr, s.retryAfter)
+		}
+	}
+}
@@ -2,11 +2,15 @@ package usecases
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
 	"sort"
 	"strings"
-	"sync"
-	"time"
+
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/ab-dauletkhan/coc/internal/domain/models"
 	"github.com/ab-dauletkhan/coc/internal/domain/ports"
@@ -16,137 +20,539 @@ type ClanEquipmentCostsUseCase struct {
 	clanAPI   ports.ClanAPI
 	playerAPI ports.PlayerAPI
 	catalog   ports.CatalogRepository
+	fanOut    *FanOut
+}
+
+func NewClanEquipmentCostsUseCase(clanAPI ports.ClanAPI, playerAPI ports.PlayerAPI, catalog ports.CatalogRepository, fanOut *FanOut) *ClanEquipmentCostsUseCase {
+	return &ClanEquipmentCostsUseCase{clanAPI: clanAPI, playerAPI: playerAPI, catalog: catalog, fanOut: fanOut}
 }
 
-func NewClanEquipmentCostsUseCase(clanAPI ports.ClanAPI, playerAPI ports.PlayerAPI, catalog ports.CatalogRepository) *ClanEquipmentCostsUseCase {
-	return &ClanEquipmentCostsUseCase{clanAPI: clanAPI, playerAPI: playerAPI, catalog: catalog}
+// MemberStatus reports how fetching a single clan member's player payload went.
+type MemberStatus string
+
+const (
+	MemberStatusOK        MemberStatus = "ok"
+	MemberStatusNotFound  MemberStatus = "not_found"
+	MemberStatusThrottled MemberStatus = "throttled"
+	MemberStatusTimeout   MemberStatus = "timeout"
+	MemberStatusError     MemberStatus = "error"
+)
+
+// Sort keys accepted by ClanCostsQuery.Sort.
+const (
+	ClanSortShiny         = "shiny"
+	ClanSortGlowy         = "glowy"
+	ClanSortStarry        = "starry"
+	ClanSortTotalWeighted = "total-weighted"
+	ClanSortName          = "name"
+	ClanSortRole          = "role"
+	ClanSortTownHall      = "townHall"
+)
+
+// ClanCostsQuery narrows, orders, and pages the members of a clan costs result.
+// Zero values select all members sorted by shiny, then glowy, then starry descending.
+type ClanCostsQuery struct {
+	Sort        string
+	Order       string // asc or desc; defaults to asc for name and desc otherwise
+	Roles       []string
+	MinTownHall int
+	Cursor      string
+	Limit       int
 }
 
+// ErrInvalidQuery is returned (with status 400) when a ClanCostsQuery cannot be applied.
+var ErrInvalidQuery = errors.New("invalid query")
+
 type ClanMemberSpend struct {
-	Tag   string           `json:"tag"`
-	Name  string           `json:"name"`
-	Spent models.OreTotals `json:"spent"`
+	Tag      string           `json:"tag"`
+	Name     string           `json:"name"`
+	Role     string           `json:"role"`
+	TownHall int              `json:"townHall"`
+	Status   MemberStatus     `json:"status"`
+	Spent    models.OreTotals `json:"spent"`
 }
 
 type ClanEquipmentCostsResult struct {
-	ClanTag string            `json:"clanTag"`
-	Total   models.OreTotals  `json:"total"`
-	Members []ClanMemberSpend `json:"members"`
+	ClanTag string           `json:"clanTag"`
+	Total   models.OreTotals `json:"total"`
+	// Complete is false when at least one member could not be fetched; such members
+	// are reported with a non-ok status and contribute nothing to Total.
+	Complete      bool                 `json:"complete"`
+	FailedMembers int                  `json:"failedMembers"`
+	Failures      map[MemberStatus]int `json:"failures,omitempty"`
+	// MatchedMembers counts members passing the filters, across all pages.
+	MatchedMembers int               `json:"matchedMembers"`
+	Members        []ClanMemberSpend `json:"members"`
+	NextCursor     string            `json:"nextCursor,omitempty"`
+}
+
+// ClanCostsSummary is the outcome of a streamed clan costs computation, without the
+// member list that was already streamed.
+type ClanCostsSummary struct {
+	ClanTag        string               `json:"clanTag"`
+	Total          models.OreTotals     `json:"total"`
+	Complete       bool                 `json:"complete"`
+	FailedMembers  int                  `json:"failedMembers"`
+	Failures       map[MemberStatus]int `json:"failures,omitempty"`
+	MatchedMembers int                  `json:"matchedMembers"`
+}
+
+func (uc *ClanEquipmentCostsUseCase) Execute(ctx context.Context, clanTag string, q ClanCostsQuery) (ClanEquipmentCostsResult, int, error) {
+	ctx, span := startSpan(ctx, "ClanEquipmentCostsUseCase.Execute", attribute.String("coc.clan_tag", clanTag))
+	res, status, err := uc.run(ctx, clanTag, q, nil)
+	endSpan(span, status, err)
+	return res, status, err
+}
+
+// Stream computes clan costs like Execute but passes each member's result to emit as
+// soon as it is fetched, in completion order. Only the member filters of q apply.
+// emit is never called when an error status is returned.
+func (uc *ClanEquipmentCostsUseCase) Stream(ctx context.Context, clanTag string, q ClanCostsQuery, emit func(ClanMemberSpend)) (ClanCostsSummary, int, error) {
+	q.Sort, q.Order, q.Cursor, q.Limit = "", "", "", 0
+	ctx, span := startSpan(ctx, "ClanEquipmentCostsUseCase.Stream", attribute.String("coc.clan_tag", clanTag))
+	res, status, err := uc.run(ctx, clanTag, q, func(f memberFetch) { emit(memberSpend(uc.catalog, f)) })
+	endSpan(span, status, err)
+	return ClanCostsSummary{
+		ClanTag:        res.ClanTag,
+		Total:          res.Total,
+		Complete:       res.Complete,
+		FailedMembers:  res.FailedMembers,
+		Failures:       res.Failures,
+		MatchedMembers: res.MatchedMembers,
+	}, status, err
 }
 
-func (uc *ClanEquipmentCostsUseCase) Execute(ctx context.Context, clanTag string) (ClanEquipmentCostsResult, int, error) {
+func (uc *ClanEquipmentCostsUseCase) run(ctx context.Context, clanTag string, q ClanCostsQuery, onResult func(memberFetch)) (ClanEquipmentCostsResult, int, error) {
 	var out ClanEquipmentCostsResult
 
-	b, status, err := uc.clanAPI.GetClanMembersRaw(ctx, clanTag)
+	less, err := memberOrder(uc.catalog, q)
+	if err != nil {
+		return out, 400, err
+	}
+	after, err := decodeCursor(q)
+	if err != nil {
+		return out, 400, err
+	}
+	if q.Limit < 0 {
+		return out, 400, fmt.Errorf("%w: limit must not be negative", ErrInvalidQuery)
+	}
+
+	members, status, err := fetchClanMembers(ctx, uc.clanAPI, clanTag)
 	if err != nil || status >= 400 {
 		return out, status, err
 	}
-	var members struct {
-		Items []struct {
-			Tag  string `json:"tag"`
-			Name string `json:"name"`
-		} `json:"items"`
-	}
-	if err := json.Unmarshal(b, &members); err != nil {
-		return out, 500, err
-	}
-
-	workerLimit := 5
-	sem := make(chan struct{}, workerLimit)
-	wg := sync.WaitGroup{}
-	results := make([]ClanMemberSpend, len(members.Items))
-
-	for i, m := range members.Items {
-		i, m := i, m
-		wg.Add(1)
-		sem <- struct{}{}
-		go func() {
-			defer wg.Done()
-			defer func() { <-sem }()
-
-			ctxp, cancelp := context.WithTimeout(ctx, 6*time.Second)
-			defer cancelp()
-			pb, pstatus, perr := uc.playerAPI.GetPlayerRaw(ctxp, normalizePlayerTag(m.Tag))
-			spent := models.OreTotals{}
-			if perr == nil && pstatus < 400 {
-				spent = uc.computePlayerOre(pb)
-			}
-			results[i] = ClanMemberSpend{Tag: m.Tag, Name: m.Name, Spent: spent}
-		}()
+	// Filter before the fan-out so excluded members cost no upstream calls.
+	members = filterClanMembers(members, q)
+
+	results := memberSpends(uc.catalog, uc.fanOut.fetchMemberPlayersEach(ctx, uc.playerAPI, members, onResult))
+	sortMemberSpends(results, less)
+
+	out.ClanTag = clanTag
+	out.Total, out.FailedMembers, out.Failures = tallyMemberSpends(results)
+	out.Complete = out.FailedMembers == 0
+	out.MatchedMembers = len(results)
+	out.Members, out.NextCursor = paginate(results, q, after, less)
+	return out, 200, nil
+}
+
+func memberSpends(catalog ports.CatalogRepository, fetched []memberFetch) []ClanMemberSpend {
+	results := make([]ClanMemberSpend, len(fetched))
+	for i, f := range fetched {
+		results[i] = memberSpend(catalog, f)
 	}
-	wg.Wait()
+	return results
+}
 
-	sort.Slice(results, func(i, j int) bool {
-		if results[i].Spent.Shiny != results[j].Spent.Shiny {
-			return results[i].Spent.Shiny > results[j].Spent.Shiny
+func memberSpend(catalog ports.CatalogRepository, f memberFetch) ClanMemberSpend {
+	mstatus := f.Status
+	spent := models.OreTotals{}
+	if mstatus == MemberStatusOK {
+		var perr error
+		if spent, perr = computePlayerOre(catalog, f.Body); perr != nil {
+			mstatus = MemberStatusError
 		}
-		if results[i].Spent.Glowy != results[j].Spent.Glowy {
-			return results[i].Spent.Glowy > results[j].Spent.Glowy
+	}
+	return ClanMemberSpend{
+		Tag:      f.Member.Tag,
+		Name:     f.Member.Name,
+		Role:     f.Member.Role,
+		TownHall: f.Member.TownHall,
+		Status:   mstatus,
+		Spent:    spent,
+	}
+}
+
+// sortMemberSpends orders results with less, keeping failed members last so they are
+// not mistaken for members who spent nothing.
+func sortMemberSpends(results []ClanMemberSpend, less func(a, b ClanMemberSpend) bool) {
+	before := memberSpendLess(less)
+	sort.SliceStable(results, func(i, j int) bool { return before(results[i], results[j]) })
+}
+
+// memberSpendLess is the order of sortMemberSpends.
+func memberSpendLess(less func(a, b ClanMemberSpend) bool) func(a, b ClanMemberSpend) bool {
+	return func(a, b ClanMemberSpend) bool {
+		aok, bok := a.Status == MemberStatusOK, b.Status == MemberStatusOK
+		if aok != bok {
+			return aok
 		}
-		return results[i].Spent.Starry > results[j].Spent.Starry
-	})
+		return less(a, b)
+	}
+}
 
+// tallyMemberSpends sums the spend of successfully fetched members and counts failures.
+func tallyMemberSpends(results []ClanMemberSpend) (models.OreTotals, int, map[MemberStatus]int) {
 	tot := models.OreTotals{}
+	failed := 0
+	var failures map[MemberStatus]int
 	for _, r := range results {
+		if r.Status != MemberStatusOK {
+			if failures == nil {
+				failures = map[MemberStatus]int{}
+			}
+			failures[r.Status]++
+			failed++
+			continue
+		}
 		tot.Shiny += r.Spent.Shiny
 		tot.Glowy += r.Spent.Glowy
 		tot.Starry += r.Spent.Starry
 	}
+	return tot, failed, failures
+}
 
-	out.ClanTag = clanTag
-	out.Total = tot
-	out.Members = results
-	return out, 200, nil
+func filterClanMembers(members []clanMember, q ClanCostsQuery) []clanMember {
+	if len(q.Roles) == 0 && q.MinTownHall <= 0 {
+		return members
+	}
+	out := make([]clanMember, 0, len(members))
+	for _, m := range members {
+		if m.TownHall < q.MinTownHall {
+			continue
+		}
+		if len(q.Roles) > 0 && !containsRole(q.Roles, m.Role) {
+			continue
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+func containsRole(roles []string, role string) bool {
+	for _, r := range roles {
+		if strings.EqualFold(canonicalRole(r), role) {
+			return true
+		}
+	}
+	return false
 }
 
-func (uc *ClanEquipmentCostsUseCase) computePlayerOre(body []byte) models.OreTotals {
-	type equipment struct {
-		Name  json.RawMessage `json:"name"`
-		Level int             `json:"level"`
+// canonicalRole maps the in-game role labels to the API role values.
+func canonicalRole(role string) string {
+	switch strings.ToLower(strings.TrimSpace(role)) {
+	case "elder":
+		return "admin"
+	case "co-leader", "co_leader", "coleader":
+		return "coLeader"
 	}
-	var p struct {
-		HeroEquipment []equipment `json:"heroEquipment"`
+	return strings.TrimSpace(role)
+}
+
+// roleRank orders roles from member (lowest) to leader (highest).
+func roleRank(role string) int {
+	switch role {
+	case "leader":
+		return 4
+	case "coLeader":
+		return 3
+	case "admin":
+		return 2
+	case "member":
+		return 1
 	}
-	if err := json.Unmarshal(body, &p); err != nil {
-		return models.OreTotals{}
+	return 0
+}
+
+// sortOrder resolves the defaults of q's sort key and direction.
+func sortOrder(q ClanCostsQuery) (key string, desc bool, err error) {
+	key = q.Sort
+	if key == "" {
+		key = ClanSortShiny
 	}
-	shiny, glowy, starry := 0, 0, 0
-	common := uc.catalog.CostsCommon()
-	epic := uc.catalog.CostsEpic()
-	for _, it := range p.HeroEquipment {
-		name := extractName(it.Name)
-		if name == "" {
-			continue
+	desc = key != ClanSortName
+	switch strings.ToLower(q.Order) {
+	case "":
+	case "asc":
+		desc = false
+	case "desc":
+		desc = true
+	default:
+		return "", false, fmt.Errorf("%w: unknown order %q", ErrInvalidQuery, q.Order)
+	}
+	return key, desc, nil
+}
+
+// memberOrder returns the order selected by q. Ties are broken by tag so the order is
+// total and a page cursor can resume after any member.
+func memberOrder(catalog ports.CatalogRepository, q ClanCostsQuery) (func(a, b ClanMemberSpend) bool, error) {
+	key, desc, err := sortOrder(q)
+	if err != nil {
+		return nil, err
+	}
+
+	var cmp func(a, b ClanMemberSpend) int
+	switch key {
+	case ClanSortShiny:
+		cmp = func(a, b ClanMemberSpend) int { return compareOre(a.Spent, b.Spent) }
+	case ClanSortGlowy:
+		cmp = func(a, b ClanMemberSpend) int {
+			if c := compareInt(a.Spent.Glowy, b.Spent.Glowy); c != 0 {
+				return c
+			}
+			return compareOre(a.Spent, b.Spent)
 		}
-		rarity := uc.catalog.GetRarity(name)
-		if rarity == "" {
-			// Unknown in catalog
-			continue
+	case ClanSortStarry:
+		cmp = func(a, b ClanMemberSpend) int {
+			if c := compareInt(a.Spent.Starry, b.Spent.Starry); c != 0 {
+				return c
+			}
+			return compareOre(a.Spent, b.Spent)
 		}
-		lvl := it.Level
-		if lvl < 0 {
-			lvl = 0
+	case ClanSortTotalWeighted:
+		glowyWeight, starryWeight := oreWeights(catalog)
+		weighted := func(t models.OreTotals) float64 {
+			return float64(t.Shiny) + float64(t.Glowy)*glowyWeight + float64(t.Starry)*starryWeight
 		}
-		var table []ports.OreCost
-		switch strings.ToUpper(rarity) {
-		case "COMMON":
-			table = common
-		case "EPIC":
-			table = epic
+		cmp = func(a, b ClanMemberSpend) int {
+			wa, wb := weighted(a.Spent), weighted(b.Spent)
+			switch {
+			case wa < wb:
+				return -1
+			case wa > wb:
+				return 1
+			}
+			return compareOre(a.Spent, b.Spent)
 		}
-		if len(table) == 0 {
-			continue
+	case ClanSortName:
+		cmp = func(a, b ClanMemberSpend) int {
+			return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+		}
+	case ClanSortRole:
+		cmp = func(a, b ClanMemberSpend) int {
+			if c := compareInt(roleRank(a.Role), roleRank(b.Role)); c != 0 {
+				return c
+			}
+			return compareOre(a.Spent, b.Spent)
+		}
+	case ClanSortTownHall:
+		cmp = func(a, b ClanMemberSpend) int {
+			if c := compareInt(a.TownHall, b.TownHall); c != 0 {
+				return c
+			}
+			return compareOre(a.Spent, b.Spent)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown sort %q", ErrInvalidQuery, q.Sort)
+	}
+	return func(a, b ClanMemberSpend) bool {
+		c := cmp(a, b)
+		if desc {
+			c = -c
 		}
-		if lvl >= len(table) {
-			lvl = len(table) - 1
+		if c != 0 {
+			return c < 0
 		}
-		for i := 0; i <= lvl; i++ {
-			shiny += table[i].Shiny
-			glowy += table[i].Glowy
-			starry += table[i].Starry
+		return a.Tag < b.Tag
+	}, nil
+}
+
+// oreWeights converts glowy and starry ore into shiny-equivalents using the ratio of
+// the total amounts each ore type takes to max out the catalog cost tables.
+func oreWeights(catalog ports.CatalogRepository) (glowy, starry float64) {
+	var tot models.OreTotals
+	for _, table := range [][]ports.OreCost{catalog.CostsCommon(), catalog.CostsEpic()} {
+		for _, c := range table {
+			tot.Shiny += c.Shiny
+			tot.Glowy += c.Glowy
+			tot.Starry += c.Starry
 		}
 	}
-	return models.OreTotals{Shiny: shiny, Glowy: glowy, Starry: starry}
+	if tot.Glowy > 0 {
+		glowy = float64(tot.Shiny) / float64(tot.Glowy)
+	}
+	if tot.Starry > 0 {
+		starry = float64(tot.Shiny) / float64(tot.Starry)
+	}
+	return glowy, starry
+}
+
+func compareOre(a, b models.OreTotals) int {
+	if c := compareInt(a.Shiny, b.Shiny); c != 0 {
+		return c
+	}
+	if c := compareInt(a.Glowy, b.Glowy); c != 0 {
+		return c
+	}
+	return compareInt(a.Starry, b.Starry)
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// clanCursor is the content of an opaque page cursor: the query it was issued for and
+// the last member of the page, after which the next page resumes. Resuming after a
+// key rather than an offset keeps pages from skipping or repeating members when the
+// clan changes between requests.
+type clanCursor struct {
+	Sort        string          `json:"sort"`
+	Desc        bool            `json:"desc"`
+	Roles       []string        `json:"roles,omitempty"`
+	MinTownHall int             `json:"minTownHall,omitempty"`
+	After       ClanMemberSpend `json:"after"`
+}
+
+// cursorQuery returns the cursor fields identifying q, with its defaults resolved and
+// its roles in canonical order.
+func cursorQuery(q ClanCostsQuery) (clanCursor, error) {
+	key, desc, err := sortOrder(q)
+	if err != nil {
+		return clanCursor{}, err
+	}
+	cur := clanCursor{Sort: key, Desc: desc}
+	for _, r := range q.Roles {
+		cur.Roles = append(cur.Roles, strings.ToLower(canonicalRole(r)))
+	}
+	slices.Sort(cur.Roles)
+	cur.Roles = slices.Compact(cur.Roles)
+	if q.MinTownHall > 0 {
+		cur.MinTownHall = q.MinTownHall
+	}
+	return cur, nil
+}
+
+func encodeCursor(q ClanCostsQuery, last ClanMemberSpend) string {
+	cur, _ := cursorQuery(q)
+	cur.After = last
+	b, _ := json.Marshal(cur)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeCursor returns the member q.Cursor resumes after, or nil without a cursor. A
+// cursor issued for another sort, order, or filter is rejected.
+func decodeCursor(q ClanCostsQuery) (*ClanMemberSpend, error) {
+	if q.Cursor == "" {
+		return nil, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(q.Cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+	var cur clanCursor
+	if err := json.Unmarshal(b, &cur); err != nil || cur.After.Tag == "" {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+	want, err := cursorQuery(q)
+	if err != nil {
+		return nil, err
+	}
+	if cur.Sort != want.Sort || cur.Desc != want.Desc || cur.MinTownHall != want.MinTownHall || !slices.Equal(cur.Roles, want.Roles) {
+		return nil, fmt.Errorf("%w: cursor does not match the sort, order, and filters", ErrInvalidQuery)
+	}
+	return &cur.After, nil
+}
+
+// paginate returns the q.Limit members following after (from the start when nil) in
+// results sorted by sortMemberSpends with less, and the cursor for the next page. A
+// zero limit returns every remaining member.
+func paginate(results []ClanMemberSpend, q ClanCostsQuery, after *ClanMemberSpend, less func(a, b ClanMemberSpend) bool) ([]ClanMemberSpend, string) {
+	start := 0
+	if after != nil {
+		before := memberSpendLess(less)
+		start = sort.Search(len(results), func(i int) bool { return before(*after, results[i]) })
+	}
+	end := len(results)
+	if q.Limit > 0 && start+q.Limit < end {
+		end = start + q.Limit
+	}
+	next := ""
+	if end < len(results) {
+		next = encodeCursor(q, results[end-1])
+	}
+	return results[start:end], next
+}
+
+func computePlayerOre(catalog ports.CatalogRepository, body []byte) (models.OreTotals, error) {
+	levels, err := parseHeroEquipment(body)
+	if err != nil {
+		return models.OreTotals{}, err
+	}
+	var tot models.OreTotals
+	for _, it := range levels {
+		// Equipment unknown to the catalog is skipped.
+		spent, _ := cumulativeOre(catalog, it.Name, it.Level)
+		tot.Add(spent)
+	}
+	return tot, nil
+}
+
+// ClanMemberEquipment is a member's spend together with the per-equipment breakdown.
+type ClanMemberEquipment struct {
+	ClanMemberSpend
+	Equipments []EquipmentSpend `json:"equipments"`
+}
+
+type ClanEquipmentReport struct {
+	ClanTag string                `json:"clanTag"`
+	Members []ClanMemberEquipment `json:"members"`
 }
 
-// inferRarity is provided by helpers.go in this package
+// Report returns every member matching the filters of q, in the order of q, with
+// per-equipment spend ordered by catalog ID. Paging does not apply.
+func (uc *ClanEquipmentCostsUseCase) Report(ctx context.Context, clanTag string, q ClanCostsQuery) (ClanEquipmentReport, int, error) {
+	ctx, span := startSpan(ctx, "ClanEquipmentCostsUseCase.Report", attribute.String("coc.clan_tag", clanTag))
+	res, status, err := uc.report(ctx, clanTag, q)
+	endSpan(span, status, err)
+	return res, status, err
+}
+
+func (uc *ClanEquipmentCostsUseCase) report(ctx context.Context, clanTag string, q ClanCostsQuery) (ClanEquipmentReport, int, error) {
+	out := ClanEquipmentReport{ClanTag: clanTag}
+	less, err := memberOrder(uc.catalog, q)
+	if err != nil {
+		return out, 400, err
+	}
+	members, status, err := fetchClanMembers(ctx, uc.clanAPI, clanTag)
+	if err != nil || status >= 400 {
+		return out, status, err
+	}
+	fetched := uc.fanOut.fetchMemberPlayers(ctx, uc.playerAPI, filterClanMembers(members, q))
+
+	spends := make([]ClanMemberSpend, len(fetched))
+	equipments := make(map[string][]EquipmentSpend, len(fetched))
+	for i, f := range fetched {
+		spends[i] = memberSpend(uc.catalog, f)
+		if spends[i].Status != MemberStatusOK {
+			continue
+		}
+		res, err := buildPlayerEquipmentCosts(uc.catalog, normalizePlayerTag(f.Member.Tag), f.Body)
+		if err != nil {
+			spends[i].Status = MemberStatusError
+			continue
+		}
+		equipments[f.Member.Tag] = res.Equipments
+	}
+	sortMemberSpends(spends, less)
+
+	out.Members = make([]ClanMemberEquipment, len(spends))
+	for i, s := range spends {
+		out.Members[i] = ClanMemberEquipment{ClanMemberSpend: s, Equipments: equipments[s.Tag]}
+	}
+	return out, 200, nil
+}
@@ -0,0 +1,121 @@
+package usecases_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	secondary "github.com/ab-dauletkhan/coc/internal/adapters/secondary"
+	"github.com/ab-dauletkhan/coc/internal/application/usecases"
+	"github.com/ab-dauletkhan/coc/internal/catalog"
+)
+
+// stubClan serves a clan whose roster the test can change between pages; every
+// member has spent nothing so name order decides.
+type stubClan struct {
+	mu      sync.Mutex
+	members []string
+}
+
+func (s *stubClan) GetClanMembersRaw(ctx context.Context, tag string) ([]byte, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := make([]string, len(s.members))
+	for i, name := range s.members {
+		items[i] = fmt.Sprintf(`{"tag":"#%s","name":%q,"role":"member","townHallLevel":15}`, strings.ToUpper(name), name)
+	}
+	return []byte(`{"items":[` + strings.Join(items, ",") + `]}`), 200, nil
+}
+
+func (s *stubClan) GetPlayerRaw(ctx context.Context, tag string) ([]byte, int, error) {
+	return fmt.Appendf(nil, `{"tag":%q,"townHallLevel":15,"heroEquipment":[]}`, tag), 200, nil
+}
+
+func newClanCostsUseCase(t *testing.T, clan *stubClan) *usecases.ClanEquipmentCostsUseCase {
+	t.Helper()
+	cat, err := catalog.LoadEquipmentCatalog("../../../data/hero_equipment.json")
+	if err != nil {
+		t.Fatalf("load catalog: %v", err)
+	}
+	return usecases.NewClanEquipmentCostsUseCase(clan, clan, secondary.NewCatalogAdapter(cat), usecases.NewFanOut(usecases.FanOutSettings{}))
+}
+
+func memberNames(res usecases.ClanEquipmentCostsResult) string {
+	names := make([]string, len(res.Members))
+	for i, m := range res.Members {
+		names[i] = m.Name
+	}
+	return strings.Join(names, ",")
+}
+
+func TestClanCostsCursorResumesAfterLastMember(t *testing.T) {
+	clan := &stubClan{members: []string{"eve", "bob", "dan", "amy", "cat"}}
+	uc := newClanCostsUseCase(t, clan)
+	q := usecases.ClanCostsQuery{Sort: usecases.ClanSortName, Limit: 2}
+
+	res, status, err := uc.Execute(context.Background(), "#C1", q)
+	if err != nil || status != 200 {
+		t.Fatalf("first page: %d %v", status, err)
+	}
+	if got := memberNames(res); got != "amy,bob" {
+		t.Fatalf("first page = %s, want amy,bob", got)
+	}
+
+	// A member of the first page leaves; an offset would now skip cat.
+	clan.mu.Lock()
+	clan.members = []string{"eve", "bob", "dan", "cat"}
+	clan.mu.Unlock()
+	q.Cursor = res.NextCursor
+	res, status, err = uc.Execute(context.Background(), "#C1", q)
+	if err != nil || status != 200 {
+		t.Fatalf("second page: %d %v", status, err)
+	}
+	if got := memberNames(res); got != "cat,dan" {
+		t.Errorf("second page = %s, want cat,dan", got)
+	}
+
+	q.Cursor = res.NextCursor
+	res, _, _ = uc.Execute(context.Background(), "#C1", q)
+	if got := memberNames(res); got != "eve" || res.NextCursor != "" {
+		t.Errorf("last page = %s (next %q), want eve without a cursor", got, res.NextCursor)
+	}
+}
+
+func TestClanCostsCursorMustMatchQuery(t *testing.T) {
+	uc := newClanCostsUseCase(t, &stubClan{members: []string{"amy", "bob", "cat"}})
+	q := usecases.ClanCostsQuery{Sort: usecases.ClanSortName, Roles: []string{"member", "elder"}, Limit: 1}
+	res, _, err := uc.Execute(context.Background(), "#C1", q)
+	if err != nil || res.NextCursor == "" {
+		t.Fatalf("first page: %v, cursor %q", err, res.NextCursor)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*usecases.ClanCostsQuery)
+		ok     bool
+	}{
+		{"same query", func(q *usecases.ClanCostsQuery) {}, true},
+		{"explicit default order", func(q *usecases.ClanCostsQuery) { q.Order = "asc" }, true},
+		{"roles reordered", func(q *usecases.ClanCostsQuery) { q.Roles = []string{"admin", "member"} }, true},
+		{"other sort", func(q *usecases.ClanCostsQuery) { q.Sort = usecases.ClanSortTownHall }, false},
+		{"other order", func(q *usecases.ClanCostsQuery) { q.Order = "desc" }, false},
+		{"other roles", func(q *usecases.ClanCostsQuery) { q.Roles = []string{"member"} }, false},
+		{"other min town hall", func(q *usecases.ClanCostsQuery) { q.MinTownHall = 10 }, false},
+		{"malformed", func(q *usecases.ClanCostsQuery) { q.Cursor = "not a cursor" }, false},
+	}
+	for _, tt := range tests {
+		next := q
+		next.Cursor = res.NextCursor
+		tt.modify(&next)
+		_, status, err := uc.Execute(context.Background(), "#C1", next)
+		if tt.ok && (err != nil || status != 200) {
+			t.Errorf("%s: %d %v, want 200", tt.name, status, err)
+		}
+		if !tt.ok && (status != 400 || !errors.Is(err, usecases.ErrInvalidQuery)) {
+			t.Errorf("%s: %d %v, want 400 ErrInvalidQuery", tt.name, status, err)
+		}
+	}
+}
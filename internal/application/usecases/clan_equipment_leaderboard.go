@@ -0,0 +1,245 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/ab-dauletkhan/coc/internal/domain/ports"
+)
+
+// ClanEquipmentLeaderboardUseCase ranks clan members by equipment level, either for a
+// single equipment or as a members × equipment matrix.
+type ClanEquipmentLeaderboardUseCase struct {
+	clanAPI   ports.ClanAPI
+	playerAPI ports.PlayerAPI
+	catalog   ports.CatalogRepository
+	fanOut    *FanOut
+}
+
+func NewClanEquipmentLeaderboardUseCase(clanAPI ports.ClanAPI, playerAPI ports.PlayerAPI, catalog ports.CatalogRepository, fanOut *FanOut) *ClanEquipmentLeaderboardUseCase {
+	return &ClanEquipmentLeaderboardUseCase{clanAPI: clanAPI, playerAPI: playerAPI, catalog: catalog, fanOut: fanOut}
+}
+
+// EquipmentOwnership summarizes how widely an equipment is unlocked among fetched members.
+type EquipmentOwnership struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Rarity string `json:"rarity"`
+	Owners int    `json:"owners"`
+	// AverageLevel is averaged over owners only; 0 when nobody owns the equipment.
+	AverageLevel float64 `json:"averageLevel"`
+}
+
+type LeaderboardEntry struct {
+	Rank     int          `json:"rank,omitempty"`
+	Tag      string       `json:"tag"`
+	Name     string       `json:"name"`
+	Status   MemberStatus `json:"status"`
+	Owned    bool         `json:"owned"`
+	Level    int          `json:"level"`
+	MaxLevel int          `json:"maxLevel"`
+}
+
+type ClanEquipmentLeaderboardResult struct {
+	ClanTag       string             `json:"clanTag"`
+	Equipment     EquipmentOwnership `json:"equipment"`
+	Complete      bool               `json:"complete"`
+	FailedMembers int                `json:"failedMembers"`
+	Entries       []LeaderboardEntry `json:"entries"`
+}
+
+type MatrixMember struct {
+	Tag      string       `json:"tag"`
+	Name     string       `json:"name"`
+	Role     string       `json:"role"`
+	TownHall int          `json:"townHall"`
+	Status   MemberStatus `json:"status"`
+	// Levels is aligned with ClanEquipmentMatrixResult.Equipment; 0 means not unlocked.
+	Levels []int `json:"levels"`
+}
+
+type ClanEquipmentMatrixResult struct {
+	ClanTag       string               `json:"clanTag"`
+	Complete      bool                 `json:"complete"`
+	FailedMembers int                  `json:"failedMembers"`
+	Equipment     []EquipmentOwnership `json:"equipment"`
+	Members       []MatrixMember       `json:"members"`
+}
+
+// Execute ranks members by their level of the given equipment. Members without it are
+// listed after owners, and members that could not be fetched come last.
+func (uc *ClanEquipmentLeaderboardUseCase) Execute(ctx context.Context, clanTag, equipment string) (ClanEquipmentLeaderboardResult, int, error) {
+	ctx, span := startSpan(ctx, "ClanEquipmentLeaderboardUseCase.Execute", attribute.String("coc.clan_tag", clanTag), attribute.String("coc.equipment", equipment))
+	res, status, err := uc.execute(ctx, clanTag, equipment)
+	endSpan(span, status, err)
+	return res, status, err
+}
+
+func (uc *ClanEquipmentLeaderboardUseCase) execute(ctx context.Context, clanTag, equipment string) (ClanEquipmentLeaderboardResult, int, error) {
+	var out ClanEquipmentLeaderboardResult
+
+	name := catalogName(uc.catalog, equipment)
+	if name == "" {
+		return out, 400, fmt.Errorf("%w: unknown equipment %q", ErrInvalidQuery, equipment)
+	}
+	members, status, err := fetchClanMembers(ctx, uc.clanAPI, clanTag)
+	if err != nil || status >= 400 {
+		return out, status, err
+	}
+	out = buildLeaderboard(uc.catalog, name, uc.fanOut.fetchMemberPlayers(ctx, uc.playerAPI, members))
+	out.ClanTag = clanTag
+	return out, 200, nil
+}
+
+// Matrix returns every member's level for every catalog equipment, with clan-wide
+// ownership counts and average levels per equipment.
+func (uc *ClanEquipmentLeaderboardUseCase) Matrix(ctx context.Context, clanTag string) (ClanEquipmentMatrixResult, int, error) {
+	ctx, span := startSpan(ctx, "ClanEquipmentLeaderboardUseCase.Matrix", attribute.String("coc.clan_tag", clanTag))
+	res, status, err := uc.matrix(ctx, clanTag)
+	endSpan(span, status, err)
+	return res, status, err
+}
+
+func (uc *ClanEquipmentLeaderboardUseCase) matrix(ctx context.Context, clanTag string) (ClanEquipmentMatrixResult, int, error) {
+	var out ClanEquipmentMatrixResult
+
+	members, status, err := fetchClanMembers(ctx, uc.clanAPI, clanTag)
+	if err != nil || status >= 400 {
+		return out, status, err
+	}
+	out = buildMatrix(uc.catalog, uc.fanOut.fetchMemberPlayers(ctx, uc.playerAPI, members))
+	out.ClanTag = clanTag
+	return out, 200, nil
+}
+
+func buildLeaderboard(catalog ports.CatalogRepository, name string, fetched []memberFetch) ClanEquipmentLeaderboardResult {
+	var out ClanEquipmentLeaderboardResult
+	entries := make([]LeaderboardEntry, 0, len(fetched))
+	levelSum := 0
+	for _, f := range fetched {
+		e := LeaderboardEntry{Tag: f.Member.Tag, Name: f.Member.Name, Status: f.Status}
+		if f.Status == MemberStatusOK {
+			levels, err := parseHeroEquipment(f.Body)
+			if err != nil {
+				e.Status = MemberStatusError
+			}
+			for _, l := range levels {
+				if strings.EqualFold(l.Name, name) {
+					e.Owned, e.Level, e.MaxLevel = true, l.Level, l.MaxLevel
+					break
+				}
+			}
+		}
+		if e.Status != MemberStatusOK {
+			out.FailedMembers++
+		} else if e.Owned {
+			out.Equipment.Owners++
+			levelSum += e.Level
+		}
+		entries = append(entries, e)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if aok, bok := a.Status == MemberStatusOK, b.Status == MemberStatusOK; aok != bok {
+			return aok
+		}
+		if a.Owned != b.Owned {
+			return a.Owned
+		}
+		if a.Level != b.Level {
+			return a.Level > b.Level
+		}
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	})
+	// Equal levels share a rank; members without the equipment or without data are unranked.
+	for i := range entries {
+		if entries[i].Status != MemberStatusOK || !entries[i].Owned {
+			break
+		}
+		if i > 0 && entries[i].Level == entries[i-1].Level {
+			entries[i].Rank = entries[i-1].Rank
+		} else {
+			entries[i].Rank = i + 1
+		}
+	}
+
+	out.Equipment.ID = catalog.GetID(name)
+	out.Equipment.Name = name
+	out.Equipment.Rarity = strings.ToUpper(catalog.GetRarity(name))
+	if out.Equipment.Owners > 0 {
+		out.Equipment.AverageLevel = float64(levelSum) / float64(out.Equipment.Owners)
+	}
+	out.Complete = out.FailedMembers == 0
+	out.Entries = entries
+	return out
+}
+
+func buildMatrix(catalog ports.CatalogRepository, fetched []memberFetch) ClanEquipmentMatrixResult {
+	var out ClanEquipmentMatrixResult
+
+	names := catalog.ListEquipmentNames()
+	sort.SliceStable(names, func(i, j int) bool {
+		if a, b := catalog.GetID(names[i]), catalog.GetID(names[j]); a != b {
+			return a < b
+		}
+		return names[i] < names[j]
+	})
+	column := make(map[string]int, len(names))
+	equipment := make([]EquipmentOwnership, len(names))
+	for i, n := range names {
+		column[strings.ToUpper(n)] = i
+		equipment[i] = EquipmentOwnership{ID: catalog.GetID(n), Name: n, Rarity: strings.ToUpper(catalog.GetRarity(n))}
+	}
+	levelSums := make([]int, len(names))
+
+	members := make([]MatrixMember, 0, len(fetched))
+	for _, f := range fetched {
+		m := MatrixMember{
+			Tag:      f.Member.Tag,
+			Name:     f.Member.Name,
+			Role:     f.Member.Role,
+			TownHall: f.Member.TownHall,
+			Status:   f.Status,
+			Levels:   make([]int, len(names)),
+		}
+		if f.Status == MemberStatusOK {
+			levels, err := parseHeroEquipment(f.Body)
+			if err != nil {
+				m.Status = MemberStatusError
+			}
+			for _, l := range levels {
+				col, ok := column[strings.ToUpper(l.Name)]
+				if !ok || l.Level <= 0 {
+					continue
+				}
+				m.Levels[col] = l.Level
+				equipment[col].Owners++
+				levelSums[col] += l.Level
+			}
+		}
+		if m.Status != MemberStatusOK {
+			out.FailedMembers++
+		}
+		members = append(members, m)
+	}
+	for i := range equipment {
+		if equipment[i].Owners > 0 {
+			equipment[i].AverageLevel = float64(levelSums[i]) / float64(equipment[i].Owners)
+		}
+	}
+	sort.SliceStable(members, func(i, j int) bool {
+		if iok, jok := members[i].Status == MemberStatusOK, members[j].Status == MemberStatusOK; iok != jok {
+			return iok
+		}
+		return strings.ToLower(members[i].Name) < strings.ToLower(members[j].Name)
+	})
+
+	out.Complete = out.FailedMembers == 0
+	out.Equipment = equipment
+	out.Members = members
+	return out
+}
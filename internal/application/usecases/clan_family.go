@@ -0,0 +1,304 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
+	"github.com/ab-dauletkhan/coc/internal/domain/ports"
+)
+
+// ClanFamilyUseCase manages clan families and aggregates equipment data across all of
+// a family's clans, counting players that appear in several member lists once.
+type ClanFamilyUseCase struct {
+	families  ports.FamilyRepository
+	clanAPI   ports.ClanAPI
+	playerAPI ports.PlayerAPI
+	catalog   ports.CatalogRepository
+	fanOut    *FanOut
+	// maxClans bounds the upstream fan-out of a single family request: with the default
+	// 3, up to 150 player fetches, which the default FANOUT_WORKERS finish within the
+	// default FAMILY_REQUEST_TIMEOUT at typical upstream latencies.
+	maxClans int
+}
+
+// NewClanFamilyUseCase returns a use case whose families hold at most maxClans clans;
+// a non-positive maxClans means 3.
+func NewClanFamilyUseCase(families ports.FamilyRepository, clanAPI ports.ClanAPI, playerAPI ports.PlayerAPI, catalog ports.CatalogRepository, fanOut *FanOut, maxClans int) *ClanFamilyUseCase {
+	if maxClans <= 0 {
+		maxClans = 3
+	}
+	return &ClanFamilyUseCase{families: families, clanAPI: clanAPI, playerAPI: playerAPI, catalog: catalog, fanOut: fanOut, maxClans: maxClans}
+}
+
+// ErrFamilyNotFound is returned (with status 404) for unknown family names.
+var ErrFamilyNotFound = errors.New("family not found")
+
+var familyNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+type Family struct {
+	Name     string   `json:"name"`
+	ClanTags []string `json:"clanTags"`
+}
+
+// FamilyClanStatus reports whether a family clan's member list could be fetched.
+type FamilyClanStatus struct {
+	ClanTag    string `json:"clanTag"`
+	Status     int    `json:"status"`
+	Members    int    `json:"members"`
+	Error      string `json:"error,omitempty"`
+	fetchError error
+}
+
+type FamilyMemberSpend struct {
+	ClanMemberSpend
+	// ClanTags lists every family clan whose member list contains the player.
+	ClanTags []string `json:"clanTags"`
+}
+
+type FamilyEquipmentCostsResult struct {
+	Family        string               `json:"family"`
+	Clans         []FamilyClanStatus   `json:"clans"`
+	Total         models.OreTotals     `json:"total"`
+	Complete      bool                 `json:"complete"`
+	FailedMembers int                  `json:"failedMembers"`
+	Failures      map[MemberStatus]int `json:"failures,omitempty"`
+	Members       []FamilyMemberSpend  `json:"members"`
+}
+
+type FamilyEquipmentLeaderboardResult struct {
+	Family        string             `json:"family"`
+	Clans         []FamilyClanStatus `json:"clans"`
+	Equipment     EquipmentOwnership `json:"equipment"`
+	Complete      bool               `json:"complete"`
+	FailedMembers int                `json:"failedMembers"`
+	Entries       []LeaderboardEntry `json:"entries"`
+}
+
+type FamilyEquipmentMatrixResult struct {
+	Family        string               `json:"family"`
+	Clans         []FamilyClanStatus   `json:"clans"`
+	Complete      bool                 `json:"complete"`
+	FailedMembers int                  `json:"failedMembers"`
+	Equipment     []EquipmentOwnership `json:"equipment"`
+	Members       []MatrixMember       `json:"members"`
+}
+
+func (uc *ClanFamilyUseCase) List(ctx context.Context) ([]Family, int, error) {
+	fams, err := uc.families.ListFamilies(ctx)
+	if err != nil {
+		return nil, 500, err
+	}
+	out := make([]Family, len(fams))
+	for i, f := range fams {
+		out[i] = Family{Name: f.Name, ClanTags: f.ClanTags}
+	}
+	return out, 200, nil
+}
+
+func (uc *ClanFamilyUseCase) Get(ctx context.Context, name string) (Family, int, error) {
+	f, ok, err := uc.families.GetFamily(ctx, name)
+	if err != nil {
+		return Family{}, 500, err
+	}
+	if !ok {
+		return Family{}, 404, ErrFamilyNotFound
+	}
+	return Family{Name: f.Name, ClanTags: f.ClanTags}, 200, nil
+}
+
+// Save creates or replaces a family. Clan tags are normalized and deduplicated.
+func (uc *ClanFamilyUseCase) Save(ctx context.Context, name string, clanTags []string) (Family, int, error) {
+	if !familyNamePattern.MatchString(name) {
+		return Family{}, 400, fmt.Errorf("%w: family name must match %s", ErrInvalidQuery, familyNamePattern)
+	}
+	seen := map[string]struct{}{}
+	tags := make([]string, 0, len(clanTags))
+	for _, t := range clanTags {
+		t = canonicalTag(t)
+		if t == "" {
+			continue
+		}
+		if _, dup := seen[t]; dup {
+			continue
+		}
+		seen[t] = struct{}{}
+		tags = append(tags, t)
+	}
+	if len(tags) == 0 {
+		return Family{}, 400, fmt.Errorf("%w: at least one clan tag is required", ErrInvalidQuery)
+	}
+	if len(tags) > uc.maxClans {
+		return Family{}, 400, fmt.Errorf("%w: a family can have at most %d clans", ErrInvalidQuery, uc.maxClans)
+	}
+	if err := uc.families.SaveFamily(ctx, models.ClanFamily{Name: name, ClanTags: tags}); err != nil {
+		return Family{}, 500, err
+	}
+	return Family{Name: name, ClanTags: tags}, 200, nil
+}
+
+func (uc *ClanFamilyUseCase) Delete(ctx context.Context, name string) (int, error) {
+	ok, err := uc.families.DeleteFamily(ctx, name)
+	if err != nil {
+		return 500, err
+	}
+	if !ok {
+		return 404, ErrFamilyNotFound
+	}
+	return 204, nil
+}
+
+// Costs aggregates ore spent across every unique player in the family's clans.
+func (uc *ClanFamilyUseCase) Costs(ctx context.Context, name string) (FamilyEquipmentCostsResult, int, error) {
+	ctx, span := startSpan(ctx, "ClanFamilyUseCase.Costs", attribute.String("app.family", name))
+	res, status, err := uc.costs(ctx, name)
+	endSpan(span, status, err)
+	return res, status, err
+}
+
+func (uc *ClanFamilyUseCase) costs(ctx context.Context, name string) (FamilyEquipmentCostsResult, int, error) {
+	var out FamilyEquipmentCostsResult
+	members, memberClans, clans, status, err := uc.fetchFamilyMembers(ctx, name)
+	if err != nil || status >= 400 {
+		return out, status, err
+	}
+	results := memberSpends(uc.catalog, uc.fanOut.fetchMemberPlayers(ctx, uc.playerAPI, members))
+	less, _ := memberOrder(uc.catalog, ClanCostsQuery{})
+	sortMemberSpends(results, less)
+
+	out.Family = name
+	out.Clans = clans
+	out.Total, out.FailedMembers, out.Failures = tallyMemberSpends(results)
+	out.Complete = out.FailedMembers == 0 && allClansFetched(clans)
+	out.Members = make([]FamilyMemberSpend, len(results))
+	for i, r := range results {
+		out.Members[i] = FamilyMemberSpend{ClanMemberSpend: r, ClanTags: memberClans[canonicalTag(r.Tag)]}
+	}
+	return out, 200, nil
+}
+
+// Leaderboard ranks every unique family player by their level of one equipment.
+func (uc *ClanFamilyUseCase) Leaderboard(ctx context.Context, name, equipment string) (FamilyEquipmentLeaderboardResult, int, error) {
+	ctx, span := startSpan(ctx, "ClanFamilyUseCase.Leaderboard", attribute.String("app.family", name), attribute.String("coc.equipment", equipment))
+	res, status, err := uc.leaderboard(ctx, name, equipment)
+	endSpan(span, status, err)
+	return res, status, err
+}
+
+func (uc *ClanFamilyUseCase) leaderboard(ctx context.Context, name, equipment string) (FamilyEquipmentLeaderboardResult, int, error) {
+	var out FamilyEquipmentLeaderboardResult
+	eqName := catalogName(uc.catalog, equipment)
+	if eqName == "" {
+		return out, 400, fmt.Errorf("%w: unknown equipment %q", ErrInvalidQuery, equipment)
+	}
+	members, _, clans, status, err := uc.fetchFamilyMembers(ctx, name)
+	if err != nil || status >= 400 {
+		return out, status, err
+	}
+	lb := buildLeaderboard(uc.catalog, eqName, uc.fanOut.fetchMemberPlayers(ctx, uc.playerAPI, members))
+	out.Family = name
+	out.Clans = clans
+	out.Equipment = lb.Equipment
+	out.Complete = lb.Complete && allClansFetched(clans)
+	out.FailedMembers = lb.FailedMembers
+	out.Entries = lb.Entries
+	return out, 200, nil
+}
+
+// Matrix returns the members × equipment matrix across the whole family.
+func (uc *ClanFamilyUseCase) Matrix(ctx context.Context, name string) (FamilyEquipmentMatrixResult, int, error) {
+	ctx, span := startSpan(ctx, "ClanFamilyUseCase.Matrix", attribute.String("app.family", name))
+	res, status, err := uc.matrix(ctx, name)
+	endSpan(span, status, err)
+	return res, status, err
+}
+
+func (uc *ClanFamilyUseCase) matrix(ctx context.Context, name string) (FamilyEquipmentMatrixResult, int, error) {
+	var out FamilyEquipmentMatrixResult
+	members, _, clans, status, err := uc.fetchFamilyMembers(ctx, name)
+	if err != nil || status >= 400 {
+		return out, status, err
+	}
+	m := buildMatrix(uc.catalog, uc.fanOut.fetchMemberPlayers(ctx, uc.playerAPI, members))
+	out.Family = name
+	out.Clans = clans
+	out.Complete = m.Complete && allClansFetched(clans)
+	out.FailedMembers = m.FailedMembers
+	out.Equipment = m.Equipment
+	out.Members = m.Members
+	return out, 200, nil
+}
+
+// fetchFamilyMembers fetches the member lists of all family clans concurrently and
+// merges them, keeping the first occurrence of each player. memberClans maps each
+// canonical player tag to the clans listing it. It fails only when no clan could be
+// fetched; otherwise per-clan failures are reported in clans.
+func (uc *ClanFamilyUseCase) fetchFamilyMembers(ctx context.Context, name string) ([]clanMember, map[string][]string, []FamilyClanStatus, int, error) {
+	fam, ok, err := uc.families.GetFamily(ctx, name)
+	if err != nil {
+		return nil, nil, nil, 500, err
+	}
+	if !ok {
+		return nil, nil, nil, 404, ErrFamilyNotFound
+	}
+
+	lists := make([][]clanMember, len(fam.ClanTags))
+	clans := make([]FamilyClanStatus, len(fam.ClanTags))
+	wg := sync.WaitGroup{}
+	for i, tag := range fam.ClanTags {
+		i, tag := i, tag
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ms, status, err := fetchClanMembers(ctx, uc.clanAPI, normalizePlayerTag(tag))
+			clans[i] = FamilyClanStatus{ClanTag: tag, Status: status, Members: len(ms), fetchError: err}
+			if err != nil {
+				clans[i].Error = err.Error()
+			}
+			lists[i] = ms
+		}()
+	}
+	wg.Wait()
+
+	var members []clanMember
+	memberClans := map[string][]string{}
+	for i, ms := range lists {
+		for _, m := range ms {
+			key := canonicalTag(m.Tag)
+			if _, dup := memberClans[key]; !dup {
+				members = append(members, m)
+			}
+			memberClans[key] = append(memberClans[key], clans[i].ClanTag)
+		}
+	}
+	if allClansFailed(clans) {
+		first := clans[0]
+		return nil, nil, clans, first.Status, first.fetchError
+	}
+	return members, memberClans, clans, 200, nil
+}
+
+func clanFetched(c FamilyClanStatus) bool { return c.fetchError == nil && c.Status < 400 }
+
+func allClansFetched(clans []FamilyClanStatus) bool {
+	for _, c := range clans {
+		if !clanFetched(c) {
+			return false
+		}
+	}
+	return true
+}
+
+func allClansFailed(clans []FamilyClanStatus) bool {
+	for _, c := range clans {
+		if clanFetched(c) {
+			return false
+		}
+	}
+	return true
+}
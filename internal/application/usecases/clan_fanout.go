@@ -0,0 +1,208 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ab-dauletkhan/coc/internal/cachehint"
+	"github.com/ab-dauletkhan/coc/internal/domain/ports"
+)
+
+// clanMember is the subset of a clan members payload entry we rely on.
+type clanMember struct {
+	Tag      string
+	Name     string
+	Role     string
+	TownHall int
+}
+
+// memberFetch is the outcome of fetching one member's player payload.
+type memberFetch struct {
+	Member clanMember
+	Status MemberStatus
+	Body   []byte
+}
+
+func fetchClanMembers(ctx context.Context, clanAPI ports.ClanAPI, clanTag string) ([]clanMember, int, error) {
+	b, status, err := clanAPI.GetClanMembersRaw(ctx, clanTag)
+	if err != nil || status >= 400 {
+		return nil, status, err
+	}
+	var members struct {
+		Items []struct {
+			Tag           string `json:"tag"`
+			Name          string `json:"name"`
+			Role          string `json:"role"`
+			TownHallLevel int    `json:"townHallLevel"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(b, &members); err != nil {
+		return nil, 500, err
+	}
+	out := make([]clanMember, len(members.Items))
+	for i, m := range members.Items {
+		out[i] = clanMember{Tag: m.Tag, Name: m.Name, Role: m.Role, TownHall: m.TownHallLevel}
+	}
+	return out, 200, nil
+}
+
+// FanOutSettings configures the shared pool used for member fetches.
+type FanOutSettings struct {
+	// Workers bounds player fetches in flight across all concurrent fan-outs.
+	Workers int
+	// MemberTimeout bounds a single player fetch.
+	MemberTimeout time.Duration
+	// Metrics, when set, receives the duration of every fan-out.
+	Metrics ports.Metrics
+}
+
+// FanOut runs player fetches for clan members, families, linked accounts, batches, and
+// the tracker on one bounded pool, so concurrent requests share the upstream budget
+// instead of multiplying it. Fetches that could not finish before the caller's
+// deadline are not started.
+type FanOut struct {
+	slots         chan struct{}
+	memberTimeout time.Duration
+	metrics       ports.Metrics
+
+	mu sync.Mutex
+	// latency is a moving average of recent fetch durations, used to predict whether a
+	// fetch can finish in time.
+	latency time.Duration
+}
+
+func NewFanOut(settings FanOutSettings) *FanOut {
+	if settings.Workers <= 0 {
+		settings.Workers = 5
+	}
+	if settings.MemberTimeout <= 0 {
+		settings.MemberTimeout = 6 * time.Second
+	}
+	return &FanOut{slots: make(chan struct{}, settings.Workers), memberTimeout: settings.MemberTimeout, metrics: settings.Metrics}
+}
+
+// fetchMemberPlayers fetches the player payload of every member concurrently.
+// Results keep the order of members; failed fetches carry a non-ok status and no body.
+func (f *FanOut) fetchMemberPlayers(ctx context.Context, playerAPI ports.PlayerAPI, members []clanMember) []memberFetch {
+	return f.fetchMemberPlayersEach(ctx, playerAPI, members, nil)
+}
+
+// fetchMemberPlayersEach is fetchMemberPlayers that also passes every result to
+// onResult as soon as it is available. Calls to onResult are serialized and all
+// happen before it returns. Members that are not fetched because ctx is done or its
+// deadline is too close are reported with MemberStatusTimeout.
+func (f *FanOut) fetchMemberPlayersEach(ctx context.Context, playerAPI ports.PlayerAPI, members []clanMember, onResult func(memberFetch)) []memberFetch {
+	if f.metrics != nil {
+		defer func(start time.Time) { f.metrics.ObserveFanOut(len(members), time.Since(start)) }(time.Now())
+	}
+	// Each fetch is a child span of this one (see coc.Client), tagged with the player.
+	ctx, span := tracer.Start(ctx, "FanOut.fetchMemberPlayers", trace.WithAttributes(
+		attribute.Int("app.members", len(members)),
+		attribute.Int("app.workers", cap(f.slots)),
+	))
+	defer span.End()
+	wg := sync.WaitGroup{}
+	mu := sync.Mutex{}
+	results := make([]memberFetch, len(members))
+	report := func(i int, res memberFetch) {
+		results[i] = res
+		if onResult != nil {
+			mu.Lock()
+			onResult(res)
+			mu.Unlock()
+		}
+	}
+
+	for i, m := range members {
+		if !f.acquire(ctx) {
+			// Nothing later can start either; report the rest without fetching.
+			wg.Wait()
+			span.SetAttributes(attribute.Int("app.members_skipped", len(members)-i))
+			cachehint.Uncacheable(ctx)
+			for j := i; j < len(members); j++ {
+				report(j, memberFetch{Member: members[j], Status: MemberStatusTimeout})
+			}
+			return results
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer f.release()
+
+			ctxp, cancelp := context.WithTimeout(ctx, f.memberTimeout)
+			defer cancelp()
+			start := time.Now()
+			pb, pstatus, perr := playerAPI.GetPlayerRaw(ctxp, normalizePlayerTag(m.Tag))
+			f.observe(time.Since(start))
+			res := memberFetch{Member: m, Status: memberStatusFor(pstatus, perr)}
+			if res.Status == MemberStatusOK {
+				res.Body = pb
+			}
+			report(i, res)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// acquire waits for a free worker and reports whether a fetch started now is expected
+// to finish before ctx's deadline.
+func (f *FanOut) acquire(ctx context.Context) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case f.slots <- struct{}{}:
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < f.expectedLatency() {
+		f.release()
+		return false
+	}
+	return true
+}
+
+func (f *FanOut) release() { <-f.slots }
+
+func (f *FanOut) expectedLatency() time.Duration {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.latency
+}
+
+func (f *FanOut) observe(d time.Duration) {
+	d = min(d, f.memberTimeout)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.latency == 0 {
+		f.latency = d
+		return
+	}
+	f.latency = (4*f.latency + d) / 5
+}
+
+// memberStatusFor classifies the outcome of a single member fetch.
+func memberStatusFor(status int, err error) MemberStatus {
+	if err != nil {
+		var nerr net.Error
+		if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &nerr) && nerr.Timeout()) {
+			return MemberStatusTimeout
+		}
+		return MemberStatusError
+	}
+	switch {
+	case status == http.StatusNotFound:
+		return MemberStatusNotFound
+	case status == http.StatusTooManyRequests:
+		return MemberStatusThrottled
+	case status >= 400 || status == 0:
+		return MemberStatusError
+	}
+	return MemberStatusOK
+}
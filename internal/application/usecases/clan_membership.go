@@ -0,0 +1,180 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
+	"github.com/ab-dauletkhan/coc/internal/domain/ports"
+)
+
+// ClanMembershipUseCase detects joins, leaves, role changes, and name changes by
+// diffing consecutive member lists of a clan, and serves the detected events.
+type ClanMembershipUseCase struct {
+	events    ports.ClanEventRepository
+	publisher ports.EventPublisher
+	now       func() time.Time
+}
+
+// NewClanMembershipUseCase returns the use case; publisher may be nil to skip
+// publishing detected events.
+func NewClanMembershipUseCase(events ports.ClanEventRepository, publisher ports.EventPublisher) *ClanMembershipUseCase {
+	return &ClanMembershipUseCase{events: events, publisher: publisher, now: time.Now}
+}
+
+type ClanEvent struct {
+	ID         string               `json:"id"`
+	ClanTag    string               `json:"clanTag"`
+	Type       models.ClanEventType `json:"type"`
+	OccurredAt time.Time            `json:"occurredAt"`
+	PlayerTag  string               `json:"playerTag"`
+	PlayerName string               `json:"playerName"`
+	OldValue   string               `json:"oldValue,omitempty"`
+	NewValue   string               `json:"newValue,omitempty"`
+}
+
+type ClanEventsResult struct {
+	ClanTag string      `json:"clanTag"`
+	Events  []ClanEvent `json:"events"`
+}
+
+// Observe compares members with the clan's previously observed member list, stores
+// and publishes the resulting events and the new list, and returns the events. The
+// first observation of a clan only records the list.
+func (uc *ClanMembershipUseCase) Observe(ctx context.Context, clanTag string, members []clanMember) ([]models.ClanEvent, error) {
+	clanTag = canonicalTag(clanTag)
+	roster := make([]models.ClanRosterMember, len(members))
+	for i, m := range members {
+		roster[i] = models.ClanRosterMember{Tag: canonicalTag(m.Tag), Name: m.Name, Role: m.Role}
+	}
+	prev, ok, err := uc.events.GetClanRoster(ctx, clanTag)
+	if err != nil {
+		return nil, err
+	}
+	var events []models.ClanEvent
+	if ok {
+		events = diffClanRoster(clanTag, prev, roster, uc.now().UTC())
+	}
+	if err := uc.events.SaveClanRoster(ctx, clanTag, roster, events); err != nil {
+		return nil, err
+	}
+	if uc.publisher != nil {
+		for _, e := range events {
+			uc.publisher.Publish(ctx, clanEvent(e))
+		}
+	}
+	return events, nil
+}
+
+// Events returns a clan's membership events within [from, to], optionally limited to
+// the given event types.
+func (uc *ClanMembershipUseCase) Events(ctx context.Context, clanTag string, from, to time.Time, types []string) (ClanEventsResult, int, error) {
+	out := ClanEventsResult{ClanTag: canonicalTag(clanTag), Events: []ClanEvent{}}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		return out, 400, fmt.Errorf("%w: from must not be after to", ErrInvalidQuery)
+	}
+	var want []models.ClanEventType
+	for _, t := range types {
+		et := models.ClanEventType(strings.TrimSpace(t))
+		switch et {
+		case models.ClanMemberJoined, models.ClanMemberLeft, models.ClanMemberPromoted, models.ClanMemberDemoted, models.ClanMemberRenamed:
+			want = append(want, et)
+		default:
+			return out, 400, fmt.Errorf("%w: unknown event type %q", ErrInvalidQuery, t)
+		}
+	}
+	events, err := uc.events.ListClanEvents(ctx, out.ClanTag, from, to, want)
+	if err != nil {
+		return out, 500, err
+	}
+	for _, e := range events {
+		out.Events = append(out.Events, toClanEvent(e))
+	}
+	return out, 200, nil
+}
+
+func toClanEvent(e models.ClanEvent) ClanEvent {
+	return ClanEvent{
+		ID:         e.ID,
+		ClanTag:    e.ClanTag,
+		Type:       e.Type,
+		OccurredAt: e.OccurredAt,
+		PlayerTag:  e.PlayerTag,
+		PlayerName: e.PlayerName,
+		OldValue:   e.OldValue,
+		NewValue:   e.NewValue,
+	}
+}
+
+// clanEvent converts a detected membership change into a published event.
+func clanEvent(e models.ClanEvent) models.Event {
+	data := map[string]any{
+		"clanTag":    e.ClanTag,
+		"playerTag":  e.PlayerTag,
+		"playerName": e.PlayerName,
+	}
+	if e.OldValue != "" || e.NewValue != "" {
+		data["oldValue"] = e.OldValue
+		data["newValue"] = e.NewValue
+	}
+	return models.Event{ID: e.ID, Type: models.ClanEventTypeFor(e.Type), OccurredAt: e.OccurredAt, Data: data}
+}
+
+// diffClanRoster lists the changes from prev to cur: leaves first, then joins, then
+// role and name changes, each ordered by player tag.
+func diffClanRoster(clanTag string, prev, cur []models.ClanRosterMember, at time.Time) []models.ClanEvent {
+	before := make(map[string]models.ClanRosterMember, len(prev))
+	for _, m := range prev {
+		before[m.Tag] = m
+	}
+	after := make(map[string]models.ClanRosterMember, len(cur))
+	for _, m := range cur {
+		after[m.Tag] = m
+	}
+	event := func(t models.ClanEventType, m models.ClanRosterMember, oldValue, newValue string) models.ClanEvent {
+		return models.ClanEvent{
+			ID:         newID(),
+			ClanTag:    clanTag,
+			Type:       t,
+			OccurredAt: at,
+			PlayerTag:  m.Tag,
+			PlayerName: m.Name,
+			OldValue:   oldValue,
+			NewValue:   newValue,
+		}
+	}
+
+	var left, joined, changed []models.ClanEvent
+	for tag, m := range before {
+		if _, ok := after[tag]; !ok {
+			left = append(left, event(models.ClanMemberLeft, m, "", ""))
+		}
+	}
+	for tag, m := range after {
+		old, ok := before[tag]
+		if !ok {
+			joined = append(joined, event(models.ClanMemberJoined, m, "", ""))
+			continue
+		}
+		if old.Role != m.Role {
+			t := models.ClanMemberPromoted
+			if roleRank(m.Role) < roleRank(old.Role) {
+				t = models.ClanMemberDemoted
+			}
+			changed = append(changed, event(t, m, old.Role, m.Role))
+		}
+		if old.Name != m.Name {
+			changed = append(changed, event(models.ClanMemberRenamed, m, old.Name, m.Name))
+		}
+	}
+	byTag := func(events []models.ClanEvent) {
+		sort.SliceStable(events, func(i, j int) bool { return events[i].PlayerTag < events[j].PlayerTag })
+	}
+	byTag(left)
+	byTag(joined)
+	byTag(changed)
+	return append(append(left, joined...), changed...)
+}
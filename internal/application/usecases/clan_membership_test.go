@@ -0,0 +1,131 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	secondary "github.com/ab-dauletkhan/coc/internal/adapters/secondary"
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
+)
+
+func TestDiffClanRoster(t *testing.T) {
+	alice := models.ClanRosterMember{Tag: "#A", Name: "Alice", Role: "member"}
+	bob := models.ClanRosterMember{Tag: "#B", Name: "Bob", Role: "admin"}
+	carol := models.ClanRosterMember{Tag: "#C", Name: "Carol", Role: "coLeader"}
+	with := func(m models.ClanRosterMember, role, name string) models.ClanRosterMember {
+		m.Role, m.Name = role, name
+		return m
+	}
+
+	tests := []struct {
+		name      string
+		prev, cur []models.ClanRosterMember
+		want      []string // type tag old→new
+	}{
+		{
+			name: "no change",
+			prev: []models.ClanRosterMember{alice, bob},
+			cur:  []models.ClanRosterMember{bob, alice},
+		},
+		{
+			name: "join",
+			prev: []models.ClanRosterMember{alice},
+			cur:  []models.ClanRosterMember{alice, bob},
+			want: []string{"member_joined #B →"},
+		},
+		{
+			name: "leave",
+			prev: []models.ClanRosterMember{alice, bob},
+			cur:  []models.ClanRosterMember{alice},
+			want: []string{"member_left #B →"},
+		},
+		{
+			name: "promotion and demotion",
+			prev: []models.ClanRosterMember{alice, bob},
+			cur:  []models.ClanRosterMember{with(alice, "admin", "Alice"), with(bob, "member", "Bob")},
+			want: []string{"member_promoted #A member→admin", "member_demoted #B admin→member"},
+		},
+		{
+			name: "rename with role change",
+			prev: []models.ClanRosterMember{alice},
+			cur:  []models.ClanRosterMember{with(alice, "coLeader", "Alicia")},
+			want: []string{"member_promoted #A member→coLeader", "member_renamed #A Alice→Alicia"},
+		},
+		{
+			name: "rejoin after leaving is a join",
+			prev: []models.ClanRosterMember{bob},
+			cur:  []models.ClanRosterMember{with(alice, "admin", "Alice"), bob},
+			want: []string{"member_joined #A →"},
+		},
+		{
+			name: "leaves then joins then changes, by tag",
+			prev: []models.ClanRosterMember{carol, bob},
+			cur:  []models.ClanRosterMember{with(carol, "leader", "Carol"), alice},
+			want: []string{"member_left #B →", "member_joined #A →", "member_promoted #C coLeader→leader"},
+		},
+	}
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := diffClanRoster("#CLAN", tt.prev, tt.cur, at)
+			got := make([]string, len(events))
+			for i, e := range events {
+				if e.ClanTag != "#CLAN" || !e.OccurredAt.Equal(at) || e.ID == "" {
+					t.Errorf("event %d = %+v, want clan #CLAN at %s with an ID", i, e, at)
+				}
+				got[i] = fmt.Sprintf("%s %s %s→%s", e.Type, e.PlayerTag, e.OldValue, e.NewValue)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("events = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestObserveRejoinPersistsRosterWithEvents checks a leave and a rejoin across
+// observations, and that the roster is on disk together with the events without
+// waiting for the store's deferred flush.
+func TestObserveRejoinPersistsRosterWithEvents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+	store, err := secondary.NewFileStore(path)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	uc := NewClanMembershipUseCase(store, nil)
+	ctx := context.Background()
+	alice := clanMember{Tag: "#A", Name: "Alice", Role: "member"}
+	bob := clanMember{Tag: "#B", Name: "Bob", Role: "member"}
+
+	var got []string
+	for _, members := range [][]clanMember{{alice, bob}, {alice}, {alice, bob}} {
+		events, err := uc.Observe(ctx, "#CLAN", members)
+		if err != nil {
+			t.Fatalf("observe: %v", err)
+		}
+		for _, e := range events {
+			got = append(got, fmt.Sprintf("%s %s", e.Type, e.PlayerTag))
+		}
+	}
+	if want := "[member_left #B member_joined #B]"; fmt.Sprint(got) != want {
+		t.Errorf("events = %v, want %s", got, want)
+	}
+
+	// Read the file back without closing the store, which would flush it.
+	reopened, err := secondary.NewFileStore(path)
+	if err != nil {
+		t.Fatalf("reopen store: %v", err)
+	}
+	defer reopened.Close()
+	defer store.Close()
+	roster, ok, _ := reopened.GetClanRoster(ctx, "#CLAN")
+	if !ok || len(roster) != 2 {
+		t.Errorf("persisted roster = %v (ok %v), want both members", roster, ok)
+	}
+	events, _ := reopened.ListClanEvents(ctx, "#CLAN", time.Time{}, time.Time{}, nil)
+	if len(events) != 2 {
+		t.Errorf("persisted %d events, want 2", len(events))
+	}
+}
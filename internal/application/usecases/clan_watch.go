@@ -0,0 +1,86 @@
+package usecases
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
+	"github.com/ab-dauletkhan/coc/internal/domain/ports"
+)
+
+// ClanWatchUseCase streams changes the tracker detects for a tracked clan: membership
+// changes and equipment unlocks and upgrades of its current members.
+type ClanWatchUseCase struct {
+	broker  *EventBroker
+	targets ports.TrackerRepository
+	rosters ports.ClanEventRepository
+}
+
+func NewClanWatchUseCase(broker *EventBroker, targets ports.TrackerRepository, rosters ports.ClanEventRepository) *ClanWatchUseCase {
+	return &ClanWatchUseCase{broker: broker, targets: targets, rosters: rosters}
+}
+
+// Watch returns a channel of the clan's events, closed when ctx is done. Clans that
+// are not tracked yield 404 since no changes would ever be detected.
+func (uc *ClanWatchUseCase) Watch(ctx context.Context, clanTag string) (<-chan PublishedEvent, int, error) {
+	clanTag = canonicalTag(clanTag)
+	targets, err := uc.targets.ListTrackedTargets(ctx)
+	if err != nil {
+		return nil, 500, err
+	}
+	tracked := false
+	for _, t := range targets {
+		if t.Kind == models.TrackedClan && t.Tag == clanTag {
+			tracked = true
+			break
+		}
+	}
+	if !tracked {
+		return nil, 404, ErrNotTracked
+	}
+
+	events, cancel := uc.broker.Subscribe(func(e models.Event) bool { return uc.concerns(ctx, clanTag, e) })
+	out := make(chan PublishedEvent)
+	go func() {
+		defer close(out)
+		defer cancel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e, ok := <-events:
+				if !ok {
+					return
+				}
+				select {
+				case out <- toPublishedEvent(e):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out, 200, nil
+}
+
+// concerns reports whether e belongs to the clan: membership events of the clan itself
+// and equipment events of players in its last observed member list.
+func (uc *ClanWatchUseCase) concerns(ctx context.Context, clanTag string, e models.Event) bool {
+	if strings.HasPrefix(string(e.Type), "clan.") {
+		return e.Data["clanTag"] == clanTag
+	}
+	playerTag, _ := e.Data["playerTag"].(string)
+	if playerTag == "" {
+		return false
+	}
+	roster, _, err := uc.rosters.GetClanRoster(ctx, clanTag)
+	if err != nil {
+		return false
+	}
+	for _, m := range roster {
+		if m.Tag == playerTag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package usecases
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
+	"github.com/ab-dauletkhan/coc/internal/domain/ports"
+)
+
+// PublishedEvent is the wire form of a models.Event, used for webhook payloads and
+// event streams.
+type PublishedEvent struct {
+	ID         string           `json:"id"`
+	Type       models.EventType `json:"type"`
+	OccurredAt time.Time        `json:"occurredAt"`
+	Data       map[string]any   `json:"data"`
+}
+
+func toPublishedEvent(e models.Event) PublishedEvent {
+	return PublishedEvent{ID: e.ID, Type: e.Type, OccurredAt: e.OccurredAt, Data: e.Data}
+}
+
+// EventPublishers publishes every event to each of its publishers in order.
+type EventPublishers []ports.EventPublisher
+
+func (p EventPublishers) Publish(ctx context.Context, event models.Event) {
+	for _, pub := range p {
+		pub.Publish(ctx, event)
+	}
+}
+
+// EventBroker is an in-process ports.EventPublisher fanning events out to live
+// subscribers. A subscriber that does not keep up misses events rather than
+// blocking publishers.
+type EventBroker struct {
+	buffer int
+
+	mu     sync.Mutex
+	next   int
+	subs   map[int]brokerSubscription
+	closed bool
+}
+
+type brokerSubscription struct {
+	ch    chan models.Event
+	match func(models.Event) bool
+}
+
+// NewEventBroker returns a broker buffering up to buffer events per subscriber.
+func NewEventBroker(buffer int) *EventBroker {
+	if buffer <= 0 {
+		buffer = 64
+	}
+	return &EventBroker{buffer: buffer, subs: map[int]brokerSubscription{}}
+}
+
+// Publish matches event outside the lock, since a match may read the store, and
+// then sends it to the matching subscribers that are still subscribed.
+func (b *EventBroker) Publish(ctx context.Context, event models.Event) {
+	b.mu.Lock()
+	subs := make(map[int]brokerSubscription, len(b.subs))
+	for id, s := range b.subs {
+		subs[id] = s
+	}
+	b.mu.Unlock()
+
+	var matched []int
+	for id, s := range subs {
+		if s.match == nil || s.match(event) {
+			matched = append(matched, id)
+		}
+	}
+	if len(matched) == 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, id := range matched {
+		// Cancel and Close remove a subscription before closing its channel.
+		s, ok := b.subs[id]
+		if !ok {
+			continue
+		}
+		select {
+		case s.ch <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel receiving published events accepted by match (nil
+// accepts all). It is closed once cancel is called or the broker is closed.
+func (b *EventBroker) Subscribe(match func(models.Event) bool) (events <-chan models.Event, cancel func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ch := make(chan models.Event, b.buffer)
+	if b.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	id := b.next
+	b.next++
+	b.subs[id] = brokerSubscription{ch: ch, match: match}
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subs[id]; ok {
+			delete(b.subs, id)
+			close(ch)
+		}
+	}
+}
+
+// Close ends every subscription, so live streams finish on shutdown; later
+// subscriptions are closed immediately.
+func (b *EventBroker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for id, s := range b.subs {
+		delete(b.subs, id)
+		close(s.ch)
+	}
+}
@@ -0,0 +1,49 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
+)
+
+// TestPublishMatchesOutsideTheLock blocks one subscriber's match, as a slow store read
+// would, and checks that the broker keeps serving other subscribers meanwhile.
+func TestPublishMatchesOutsideTheLock(t *testing.T) {
+	b := NewEventBroker(1)
+	defer b.Close()
+	entered, release := make(chan struct{}), make(chan struct{})
+	slow, cancelSlow := b.Subscribe(func(models.Event) bool {
+		close(entered)
+		<-release
+		return true
+	})
+	defer cancelSlow()
+
+	published := make(chan struct{})
+	go func() {
+		b.Publish(context.Background(), models.Event{ID: "e1"})
+		close(published)
+	}()
+	<-entered
+
+	done := make(chan struct{})
+	go func() {
+		_, cancel := b.Subscribe(nil)
+		cancel()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		close(release)
+		t.Fatal("subscribing waited for another subscriber's match")
+	}
+
+	close(release)
+	<-published
+	if e := <-slow; e.ID != "e1" {
+		t.Errorf("slow subscriber got %+v, want e1", e)
+	}
+}
@@ -0,0 +1,151 @@
+package usecases
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ab-dauletkhan/coc/internal/domain/ports"
+)
+
+// HealthSettings configures the readiness checks.
+type HealthSettings struct {
+	// TokenConfigured is false when no upstream API token is set.
+	TokenConfigured bool
+	// ProbeAfter is how old the last upstream outcome may be before a check probes
+	// the API itself.
+	ProbeAfter time.Duration
+	// ProbeTimeout bounds a probe.
+	ProbeTimeout time.Duration
+}
+
+// HealthUseCase reports whether the service can serve requests: the catalog is loaded,
+// the store is writable, and the official API accepts our token from this IP.
+type HealthUseCase struct {
+	upstream ports.UpstreamMonitor
+	catalog  ports.CatalogRepository
+	store    ports.StoreHealth
+	settings HealthSettings
+	now      func() time.Time
+
+	probeMu sync.Mutex
+}
+
+func NewHealthUseCase(upstream ports.UpstreamMonitor, catalog ports.CatalogRepository, store ports.StoreHealth, settings HealthSettings) *HealthUseCase {
+	if settings.ProbeAfter <= 0 {
+		settings.ProbeAfter = 30 * time.Second
+	}
+	if settings.ProbeTimeout <= 0 {
+		settings.ProbeTimeout = 3 * time.Second
+	}
+	return &HealthUseCase{upstream: upstream, catalog: catalog, store: store, settings: settings, now: time.Now}
+}
+
+type HealthReport struct {
+	Ready bool `json:"ready"`
+	// Reasons lists why the service is not ready; empty when ready.
+	Reasons  []string       `json:"reasons"`
+	Catalog  CatalogHealth  `json:"catalog"`
+	Upstream UpstreamHealth `json:"upstream"`
+	Store    StoreHealth    `json:"store"`
+}
+
+type CatalogHealth struct {
+	Loaded   bool       `json:"loaded"`
+	Items    int        `json:"items"`
+	Version  string     `json:"version,omitempty"`
+	Checksum string     `json:"checksum,omitempty"`
+	LoadedAt *time.Time `json:"loadedAt,omitempty"`
+}
+
+type UpstreamHealth struct {
+	TokenConfigured bool       `json:"tokenConfigured"`
+	LastSuccessAt   *time.Time `json:"lastSuccessAt,omitempty"`
+	LastErrorAt     *time.Time `json:"lastErrorAt,omitempty"`
+	// LastError is the reason of the last failed call, e.g. "accessDenied.invalidIp".
+	LastError       string `json:"lastError,omitempty"`
+	LastErrorStatus int    `json:"lastErrorStatus,omitempty"`
+	Circuit         string `json:"circuit"`
+}
+
+type StoreHealth struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// Check runs every readiness check. The API is probed when nothing was requested from
+// it within ProbeAfter; concurrent checks share a probe.
+func (uc *HealthUseCase) Check(ctx context.Context) HealthReport {
+	var out HealthReport
+
+	info := uc.catalog.Info()
+	out.Catalog = CatalogHealth{
+		Loaded:   info.Items > 0,
+		Items:    info.Items,
+		Version:  info.Version,
+		Checksum: info.Checksum,
+		LoadedAt: optionalTime(info.LoadedAt),
+	}
+	if !out.Catalog.Loaded {
+		out.Reasons = append(out.Reasons, "equipment catalog is not loaded")
+	}
+
+	if err := uc.store.Ping(ctx); err != nil {
+		out.Store = StoreHealth{Error: err.Error()}
+		out.Reasons = append(out.Reasons, "store is not writable: "+err.Error())
+	} else {
+		out.Store = StoreHealth{OK: true}
+	}
+
+	out.Upstream.TokenConfigured = uc.settings.TokenConfigured
+	if !uc.settings.TokenConfigured {
+		out.Reasons = append(out.Reasons, "COC_API_TOKEN is not set")
+	} else {
+		uc.probeIfStale(ctx)
+	}
+	h := uc.upstream.UpstreamHealth()
+	out.Upstream.LastSuccessAt = optionalTime(h.LastSuccessAt)
+	out.Upstream.LastErrorAt = optionalTime(h.LastErrorAt)
+	out.Upstream.LastError = h.LastError
+	out.Upstream.LastErrorStatus = h.LastErrorStatus
+	out.Upstream.Circuit = h.Circuit
+	switch {
+	case h.Circuit == "open":
+		out.Reasons = append(out.Reasons, "upstream circuit is open")
+	case uc.settings.TokenConfigured && !h.LastErrorAt.Before(h.LastSuccessAt) && !h.LastErrorAt.IsZero():
+		out.Reasons = append(out.Reasons, "last upstream call failed: "+h.LastError)
+	case uc.settings.TokenConfigured && h.LastSuccessAt.IsZero():
+		out.Reasons = append(out.Reasons, "upstream has not been reached")
+	}
+
+	out.Ready = len(out.Reasons) == 0
+	if out.Reasons == nil {
+		out.Reasons = []string{}
+	}
+	return out
+}
+
+func (uc *HealthUseCase) probeIfStale(ctx context.Context) {
+	uc.probeMu.Lock()
+	defer uc.probeMu.Unlock()
+	h := uc.upstream.UpstreamHealth()
+	last := h.LastSuccessAt
+	if h.LastErrorAt.After(last) {
+		last = h.LastErrorAt
+	}
+	if uc.now().Sub(last) < uc.settings.ProbeAfter {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, uc.settings.ProbeTimeout)
+	defer cancel()
+	// The outcome is read back from UpstreamHealth.
+	_ = uc.upstream.Probe(ctx)
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	t = t.UTC()
+	return &t
+}
@@ -1,8 +1,13 @@
 package usecases
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"strings"
+
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
+	"github.com/ab-dauletkhan/coc/internal/domain/ports"
 )
 
 func extractName(raw json.RawMessage) string {
@@ -40,3 +45,92 @@ func normalizePlayerTag(tag string) string {
 	}
 	return "%23" + tag
 }
+
+// parseHeroEquipment extracts the hero equipment levels from a raw player payload.
+func parseHeroEquipment(body []byte) ([]models.EquipmentLevel, error) {
+	type equipment struct {
+		Name     json.RawMessage `json:"name"`
+		Level    int             `json:"level"`
+		MaxLevel int             `json:"maxLevel"`
+	}
+	var p struct {
+		HeroEquipment []equipment `json:"heroEquipment"`
+	}
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, err
+	}
+	out := make([]models.EquipmentLevel, 0, len(p.HeroEquipment))
+	for _, it := range p.HeroEquipment {
+		name := extractName(it.Name)
+		if name == "" {
+			continue
+		}
+		out = append(out, models.EquipmentLevel{Name: name, Level: it.Level, MaxLevel: it.MaxLevel})
+	}
+	return out, nil
+}
+
+// catalogName returns the catalog spelling of an equipment name or empty when unknown.
+func catalogName(catalog ports.CatalogRepository, name string) string {
+	name = strings.TrimSpace(name)
+	for _, n := range catalog.ListEquipmentNames() {
+		if strings.EqualFold(n, name) {
+			return n
+		}
+	}
+	return ""
+}
+
+// canonicalTag returns a player or clan tag in its display form (#ABC123), accepting
+// the same spellings as normalizePlayerTag.
+func canonicalTag(tag string) string {
+	tag = strings.TrimSpace(tag)
+	tag = strings.TrimPrefix(tag, "%23")
+	tag = strings.TrimPrefix(tag, "#")
+	if tag == "" {
+		return ""
+	}
+	return "#" + strings.ToUpper(tag)
+}
+
+// cumulativeOre returns the ore needed to bring the named equipment from nothing to
+// level using the catalog cost tables. ok is false when the equipment or its rarity
+// table is unknown.
+func cumulativeOre(catalog ports.CatalogRepository, name string, level int) (models.OreTotals, bool) {
+	table := costTable(catalog, name)
+	if len(table) == 0 {
+		return models.OreTotals{}, false
+	}
+	if level < 0 {
+		level = 0
+	}
+	if level >= len(table) {
+		level = len(table) - 1
+	}
+	var out models.OreTotals
+	for i := 0; i <= level; i++ {
+		out.Shiny += table[i].Shiny
+		out.Glowy += table[i].Glowy
+		out.Starry += table[i].Starry
+	}
+	return out, true
+}
+
+// costTable returns the per-level cost table for the named equipment's rarity, indexed
+// by level, or nil when the equipment or its rarity is unknown.
+func costTable(catalog ports.CatalogRepository, name string) []ports.OreCost {
+	switch strings.ToUpper(catalog.GetRarity(name)) {
+	case "COMMON":
+		return catalog.CostsCommon()
+	case "EPIC":
+		return catalog.CostsEpic()
+	}
+	return nil
+}
+
+// newID returns a random identifier for stored records.
+func newID() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
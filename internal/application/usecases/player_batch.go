@@ -0,0 +1,207 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
+	"github.com/ab-dauletkhan/coc/internal/domain/ports"
+)
+
+// PlayerBatchUseCase returns several views of many players in one call, fetching each
+// player once on the shared fan-out pool.
+type PlayerBatchUseCase struct {
+	playerAPI ports.PlayerAPI
+	catalog   ports.CatalogRepository
+	fanOut    *FanOut
+}
+
+func NewPlayerBatchUseCase(playerAPI ports.PlayerAPI, catalog ports.CatalogRepository, fanOut *FanOut) *PlayerBatchUseCase {
+	return &PlayerBatchUseCase{playerAPI: playerAPI, catalog: catalog, fanOut: fanOut}
+}
+
+// Views accepted by PlayerBatchUseCase.Execute.
+const (
+	PlayerViewEquipment = "equipment"
+	PlayerViewCosts     = "costs"
+	PlayerViewPlan      = "plan"
+)
+
+// maxBatchPlayers bounds the tags accepted in one batch request.
+const maxBatchPlayers = 100
+
+type PlanItem struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Rarity string `json:"rarity"`
+	Level  int    `json:"level"`
+	// MaxLevel is the player's reported max level, or the catalog's highest level when
+	// the payload does not report one.
+	MaxLevel  int              `json:"maxLevel"`
+	Remaining models.OreTotals `json:"remaining"`
+}
+
+// PlayerUpgradePlan lists the ore still needed to max every unlocked equipment.
+type PlayerUpgradePlan struct {
+	Remaining  models.OreTotals `json:"remaining"`
+	Equipments []PlanItem       `json:"equipments"`
+}
+
+type PlayerBatchItem struct {
+	Tag       string                      `json:"tag"`
+	Status    MemberStatus                `json:"status"`
+	Error     string                      `json:"error,omitempty"`
+	Equipment *PlayerHeroEquipmentsResult `json:"equipment,omitempty"`
+	Costs     *PlayerEquipmentCostsResult `json:"costs,omitempty"`
+	Plan      *PlayerUpgradePlan          `json:"plan,omitempty"`
+}
+
+type PlayerBatchResult struct {
+	Requested int               `json:"requested"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Results   []PlayerBatchItem `json:"results"`
+}
+
+// Execute fetches every distinct tag and builds the requested views (equipment and
+// costs when none are given). Results keep the order of the first occurrence of each
+// tag; a failed player carries a non-ok status and an error instead of views.
+func (uc *PlayerBatchUseCase) Execute(ctx context.Context, tags, views []string) (PlayerBatchResult, int, error) {
+	ctx, span := startSpan(ctx, "PlayerBatchUseCase.Execute", attribute.Int("coc.player_count", len(tags)), attribute.StringSlice("app.views", views))
+	res, status, err := uc.execute(ctx, tags, views)
+	endSpan(span, status, err)
+	return res, status, err
+}
+
+func (uc *PlayerBatchUseCase) execute(ctx context.Context, tags, views []string) (PlayerBatchResult, int, error) {
+	var out PlayerBatchResult
+	if len(views) == 0 {
+		views = []string{PlayerViewEquipment, PlayerViewCosts}
+	}
+	for _, v := range views {
+		if v != PlayerViewEquipment && v != PlayerViewCosts && v != PlayerViewPlan {
+			return out, 400, fmt.Errorf("%w: unknown view %q", ErrInvalidQuery, v)
+		}
+	}
+	var members []clanMember
+	seen := map[string]bool{}
+	for _, t := range tags {
+		tag := canonicalTag(t)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		members = append(members, clanMember{Tag: tag})
+	}
+	if len(members) == 0 {
+		return out, 400, fmt.Errorf("%w: at least one tag is required", ErrInvalidQuery)
+	}
+	if len(members) > maxBatchPlayers {
+		return out, 400, fmt.Errorf("%w: at most %d tags are allowed", ErrInvalidQuery, maxBatchPlayers)
+	}
+
+	out.Requested = len(members)
+	out.Results = make([]PlayerBatchItem, 0, len(members))
+	for _, f := range uc.fanOut.fetchMemberPlayers(ctx, uc.playerAPI, members) {
+		item := uc.buildItem(f, views)
+		if item.Status == MemberStatusOK {
+			out.Succeeded++
+		} else {
+			out.Failed++
+		}
+		out.Results = append(out.Results, item)
+	}
+	return out, 200, nil
+}
+
+func (uc *PlayerBatchUseCase) buildItem(f memberFetch, views []string) PlayerBatchItem {
+	item := PlayerBatchItem{Tag: f.Member.Tag, Status: f.Status}
+	if f.Status != MemberStatusOK {
+		item.Error = batchErrorFor(f.Status)
+		return item
+	}
+	tag := normalizePlayerTag(f.Member.Tag)
+	var err error
+	if slices.Contains(views, PlayerViewEquipment) {
+		var res PlayerHeroEquipmentsResult
+		if res, err = buildPlayerHeroEquipments(uc.catalog, tag, f.Body); err == nil {
+			item.Equipment = &res
+		}
+	}
+	if err == nil && slices.Contains(views, PlayerViewCosts) {
+		var res PlayerEquipmentCostsResult
+		if res, err = buildPlayerEquipmentCosts(uc.catalog, tag, f.Body); err == nil {
+			item.Costs = &res
+		}
+	}
+	if err == nil && slices.Contains(views, PlayerViewPlan) {
+		var res PlayerUpgradePlan
+		if res, err = buildUpgradePlan(uc.catalog, f.Body); err == nil {
+			item.Plan = &res
+		}
+	}
+	if err != nil {
+		return PlayerBatchItem{Tag: item.Tag, Status: MemberStatusError, Error: err.Error()}
+	}
+	return item
+}
+
+func batchErrorFor(status MemberStatus) string {
+	switch status {
+	case MemberStatusNotFound:
+		return "player not found"
+	case MemberStatusThrottled:
+		return "upstream rate limit exceeded"
+	case MemberStatusTimeout:
+		return "upstream request timed out"
+	}
+	return "upstream request failed"
+}
+
+// buildUpgradePlan computes the ore needed to bring every unlocked equipment known to
+// the catalog from its current to its max level.
+func buildUpgradePlan(catalog ports.CatalogRepository, body []byte) (PlayerUpgradePlan, error) {
+	out := PlayerUpgradePlan{Equipments: []PlanItem{}}
+	levels, err := parseHeroEquipment(body)
+	if err != nil {
+		return out, err
+	}
+	for _, l := range levels {
+		table := costTable(catalog, l.Name)
+		if len(table) == 0 {
+			continue
+		}
+		maxLevel := l.MaxLevel
+		if maxLevel <= 0 || maxLevel >= len(table) {
+			maxLevel = len(table) - 1
+		}
+		// The catalog may lag behind the game; never plan below the current level.
+		maxLevel = max(maxLevel, l.Level)
+		item := PlanItem{
+			ID:       catalog.GetID(l.Name),
+			Name:     l.Name,
+			Rarity:   strings.ToUpper(catalog.GetRarity(l.Name)),
+			Level:    l.Level,
+			MaxLevel: maxLevel,
+		}
+		if l.Level < maxLevel {
+			hi, _ := cumulativeOre(catalog, l.Name, maxLevel)
+			lo, _ := cumulativeOre(catalog, l.Name, l.Level)
+			item.Remaining = hi.Sub(lo)
+		}
+		out.Remaining.Add(item.Remaining)
+		out.Equipments = append(out.Equipments, item)
+	}
+	sort.Slice(out.Equipments, func(i, j int) bool {
+		if out.Equipments[i].ID != out.Equipments[j].ID {
+			return out.Equipments[i].ID < out.Equipments[j].ID
+		}
+		return out.Equipments[i].Name < out.Equipments[j].Name
+	})
+	return out, nil
+}
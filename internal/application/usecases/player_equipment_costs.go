@@ -6,6 +6,8 @@ import (
 	"sort"
 	"strings"
 
+	"go.opentelemetry.io/otel/attribute"
+
 	"github.com/ab-dauletkhan/coc/internal/domain/models"
 	"github.com/ab-dauletkhan/coc/internal/domain/ports"
 )
@@ -35,12 +37,29 @@ type PlayerEquipmentCostsResult struct {
 }
 
 func (uc *PlayerEquipmentCostsUseCase) Execute(ctx context.Context, playerTag string) (PlayerEquipmentCostsResult, int, error) {
+	ctx, span := startSpan(ctx, "PlayerEquipmentCostsUseCase.Execute", attribute.String("coc.player_tag", playerTag))
+	res, status, err := uc.execute(ctx, playerTag)
+	endSpan(span, status, err)
+	return res, status, err
+}
+
+func (uc *PlayerEquipmentCostsUseCase) execute(ctx context.Context, playerTag string) (PlayerEquipmentCostsResult, int, error) {
 	var out PlayerEquipmentCostsResult
 
 	body, status, err := uc.playerAPI.GetPlayerRaw(ctx, playerTag)
 	if err != nil || status >= 400 {
 		return out, status, err
 	}
+	out, err = buildPlayerEquipmentCosts(uc.catalog, playerTag, body)
+	if err != nil {
+		return out, 500, err
+	}
+	return out, 200, nil
+}
+
+// buildPlayerEquipmentCosts computes the costs result from a raw player payload.
+func buildPlayerEquipmentCosts(catalog ports.CatalogRepository, playerTag string, body []byte) (PlayerEquipmentCostsResult, error) {
+	var out PlayerEquipmentCostsResult
 	type equipment struct {
 		Name  json.RawMessage `json:"name"`
 		Level int             `json:"level"`
@@ -49,11 +68,11 @@ func (uc *PlayerEquipmentCostsUseCase) Execute(ctx context.Context, playerTag st
 		HeroEquipment []equipment `json:"heroEquipment"`
 	}
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return out, 500, err
+		return out, err
 	}
 
-	catCommon := uc.catalog.CostsCommon()
-	catEpic := uc.catalog.CostsEpic()
+	catCommon := catalog.CostsCommon()
+	catEpic := catalog.CostsEpic()
 
 	var total models.OreTotals
 	results := make([]EquipmentSpend, 0, len(resp.HeroEquipment))
@@ -62,7 +81,7 @@ func (uc *PlayerEquipmentCostsUseCase) Execute(ctx context.Context, playerTag st
 		if name == "" {
 			continue
 		}
-		rarity := uc.catalog.GetRarity(name)
+		rarity := catalog.GetRarity(name)
 		if rarity == "" {
 			// Unknown in catalog, skip from cost computation as we cannot determine table
 			continue
@@ -99,7 +118,7 @@ func (uc *PlayerEquipmentCostsUseCase) Execute(ctx context.Context, playerTag st
 			Rarity: strings.ToUpper(rarity),
 			Level:  it.Level,
 			Spent:  spent,
-			ID:     uc.catalog.GetID(name),
+			ID:     catalog.GetID(name),
 		})
 	}
 	sort.Slice(results, func(i, j int) bool {
@@ -111,7 +130,7 @@ func (uc *PlayerEquipmentCostsUseCase) Execute(ctx context.Context, playerTag st
 	out.PlayerTag = playerTag
 	out.Total = total
 	out.Equipments = results
-	return out, 200, nil
+	return out, nil
 }
 
 // helpers are provided by helpers.go in this package
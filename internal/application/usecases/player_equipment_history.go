@@ -0,0 +1,242 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
+	"github.com/ab-dauletkhan/coc/internal/domain/ports"
+)
+
+// PlayerEquipmentHistoryUseCase derives equipment progress and ore spent over time
+// from stored player snapshots.
+type PlayerEquipmentHistoryUseCase struct {
+	snapshots ports.SnapshotRepository
+	catalog   ports.CatalogRepository
+	now       func() time.Time
+}
+
+func NewPlayerEquipmentHistoryUseCase(snapshots ports.SnapshotRepository, catalog ports.CatalogRepository) *PlayerEquipmentHistoryUseCase {
+	return &PlayerEquipmentHistoryUseCase{snapshots: snapshots, catalog: catalog, now: time.Now}
+}
+
+// ErrNoSnapshots is returned (with status 404) when no history is stored for a player.
+var ErrNoSnapshots = errors.New("no snapshots recorded for player")
+
+// defaultHistoryWindow is used when the caller does not specify from.
+const defaultHistoryWindow = 30 * 24 * time.Hour
+
+type LevelChange struct {
+	At    time.Time `json:"at"`
+	Level int       `json:"level"`
+}
+
+type EquipmentHistory struct {
+	ID        int              `json:"id"`
+	Name      string           `json:"name"`
+	Rarity    string           `json:"rarity"`
+	FromLevel int              `json:"fromLevel"`
+	ToLevel   int              `json:"toLevel"`
+	Spent     models.OreTotals `json:"spent"`
+	Timeline  []LevelChange    `json:"timeline"`
+}
+
+type EquipmentChange struct {
+	Name      string `json:"name"`
+	FromLevel int    `json:"fromLevel"`
+	ToLevel   int    `json:"toLevel"`
+}
+
+// HistoryInterval covers the time between two consecutive snapshots: the upgrades
+// happened after From (the earlier snapshot was last seen) and before To.
+type HistoryInterval struct {
+	From    time.Time         `json:"from"`
+	To      time.Time         `json:"to"`
+	Changes []EquipmentChange `json:"changes"`
+	Spent   models.OreTotals  `json:"spent"`
+}
+
+type WeeklySpend struct {
+	WeekStart time.Time        `json:"weekStart"`
+	Spent     models.OreTotals `json:"spent"`
+}
+
+type OreRate struct {
+	Shiny  float64 `json:"shiny"`
+	Glowy  float64 `json:"glowy"`
+	Starry float64 `json:"starry"`
+}
+
+type HistorySummary struct {
+	// Weeks is the covered span in weeks, from the baseline snapshot (or from) to to.
+	Weeks   float64 `json:"weeks"`
+	PerWeek OreRate `json:"perWeek"`
+}
+
+type PlayerEquipmentHistoryResult struct {
+	PlayerTag  string             `json:"playerTag"`
+	From       time.Time          `json:"from"`
+	To         time.Time          `json:"to"`
+	Snapshots  int                `json:"snapshots"`
+	Spent      models.OreTotals   `json:"spent"`
+	Summary    HistorySummary     `json:"summary"`
+	Weekly     []WeeklySpend      `json:"weekly"`
+	Intervals  []HistoryInterval  `json:"intervals"`
+	Equipments []EquipmentHistory `json:"equipments"`
+}
+
+// Execute returns the level changes between from and to (zero values select the last
+// 30 days up to now). The latest snapshot before from is used as the starting point;
+// without one, spending can only be measured from the first snapshot in range.
+func (uc *PlayerEquipmentHistoryUseCase) Execute(ctx context.Context, playerTag string, from, to time.Time) (PlayerEquipmentHistoryResult, int, error) {
+	ctx, span := startSpan(ctx, "PlayerEquipmentHistoryUseCase.Execute", attribute.String("coc.player_tag", playerTag))
+	res, status, err := uc.execute(ctx, playerTag, from, to)
+	endSpan(span, status, err)
+	return res, status, err
+}
+
+func (uc *PlayerEquipmentHistoryUseCase) execute(ctx context.Context, playerTag string, from, to time.Time) (PlayerEquipmentHistoryResult, int, error) {
+	var out PlayerEquipmentHistoryResult
+	if to.IsZero() {
+		to = uc.now()
+	}
+	if from.IsZero() {
+		from = to.Add(-defaultHistoryWindow)
+	}
+	if !from.Before(to) {
+		return out, 400, fmt.Errorf("%w: from must be before to", ErrInvalidQuery)
+	}
+	tag := canonicalTag(playerTag)
+	snaps, err := uc.snapshots.ListSnapshots(ctx, tag, from, to)
+	if err != nil {
+		return out, 500, err
+	}
+	if len(snaps) == 0 {
+		return out, 404, ErrNoSnapshots
+	}
+
+	out.PlayerTag = tag
+	out.From = from.UTC()
+	out.To = to.UTC()
+	out.Snapshots = len(snaps)
+	out.Intervals = []HistoryInterval{}
+
+	base := snaps[0]
+	histories := map[string]*EquipmentHistory{}
+	history := func(name string) *EquipmentHistory {
+		key := strings.ToUpper(name)
+		h, ok := histories[key]
+		if !ok {
+			h = &EquipmentHistory{
+				ID:       uc.catalog.GetID(name),
+				Name:     name,
+				Rarity:   strings.ToUpper(uc.catalog.GetRarity(name)),
+				Timeline: []LevelChange{},
+			}
+			histories[key] = h
+		}
+		return h
+	}
+	for _, e := range base.Equipment {
+		h := history(e.Name)
+		h.FromLevel, h.ToLevel = e.Level, e.Level
+		h.Timeline = append(h.Timeline, LevelChange{At: base.TakenAt, Level: e.Level})
+	}
+
+	weekly := map[time.Time]*WeeklySpend{}
+	prev := base
+	for _, cur := range snaps[1:] {
+		iv := HistoryInterval{From: prev.LastSeenAt, To: cur.TakenAt, Changes: []EquipmentChange{}}
+		before := equipmentLevels(prev)
+		for _, e := range cur.Equipment {
+			old := before[strings.ToUpper(e.Name)]
+			if e.Level == old {
+				continue
+			}
+			iv.Changes = append(iv.Changes, EquipmentChange{Name: e.Name, FromLevel: old, ToLevel: e.Level})
+			h := history(e.Name)
+			h.ToLevel = e.Level
+			h.Timeline = append(h.Timeline, LevelChange{At: cur.TakenAt, Level: e.Level})
+			if e.Level > old {
+				spent := uc.oreBetween(e.Name, old, e.Level)
+				iv.Spent.Add(spent)
+				h.Spent.Add(spent)
+			}
+		}
+		prev = cur
+		if len(iv.Changes) == 0 {
+			continue
+		}
+		out.Spent.Add(iv.Spent)
+		out.Intervals = append(out.Intervals, iv)
+		week := weekStart(iv.To)
+		w, ok := weekly[week]
+		if !ok {
+			w = &WeeklySpend{WeekStart: week}
+			weekly[week] = w
+		}
+		w.Spent.Add(iv.Spent)
+	}
+
+	out.Weekly = make([]WeeklySpend, 0, len(weekly))
+	for _, w := range weekly {
+		out.Weekly = append(out.Weekly, *w)
+	}
+	sort.Slice(out.Weekly, func(i, j int) bool { return out.Weekly[i].WeekStart.Before(out.Weekly[j].WeekStart) })
+
+	out.Equipments = make([]EquipmentHistory, 0, len(histories))
+	for _, h := range histories {
+		out.Equipments = append(out.Equipments, *h)
+	}
+	sort.Slice(out.Equipments, func(i, j int) bool {
+		if out.Equipments[i].ID != out.Equipments[j].ID {
+			return out.Equipments[i].ID < out.Equipments[j].ID
+		}
+		return out.Equipments[i].Name < out.Equipments[j].Name
+	})
+
+	start := from
+	if base.TakenAt.After(start) {
+		start = base.TakenAt
+	}
+	if weeks := to.Sub(start).Hours() / (24 * 7); weeks > 0 {
+		out.Summary.Weeks = weeks
+		out.Summary.PerWeek = OreRate{
+			Shiny:  float64(out.Spent.Shiny) / weeks,
+			Glowy:  float64(out.Spent.Glowy) / weeks,
+			Starry: float64(out.Spent.Starry) / weeks,
+		}
+	}
+	return out, 200, nil
+}
+
+// oreBetween returns the ore needed to upgrade the named equipment from one level to another.
+func (uc *PlayerEquipmentHistoryUseCase) oreBetween(name string, from, to int) models.OreTotals {
+	hi, ok := cumulativeOre(uc.catalog, name, to)
+	if !ok {
+		return models.OreTotals{}
+	}
+	lo, _ := cumulativeOre(uc.catalog, name, from)
+	return hi.Sub(lo)
+}
+
+func equipmentLevels(s models.PlayerSnapshot) map[string]int {
+	out := make(map[string]int, len(s.Equipment))
+	for _, e := range s.Equipment {
+		out[strings.ToUpper(e.Name)] = e.Level
+	}
+	return out
+}
+
+// weekStart returns midnight UTC of the Monday starting t's week.
+func weekStart(t time.Time) time.Time {
+	t = t.UTC()
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, time.UTC)
+}
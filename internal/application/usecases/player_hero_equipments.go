@@ -6,6 +6,8 @@ import (
 	"net/url"
 	"sort"
 
+	"go.opentelemetry.io/otel/attribute"
+
 	"github.com/ab-dauletkhan/coc/internal/domain/ports"
 )
 
@@ -33,6 +35,13 @@ type Equipment struct {
 }
 
 func (uc *PlayerHeroEquipmentsUseCase) Execute(ctx context.Context, playerTag string) (PlayerHeroEquipmentsResult, int, error) {
+	ctx, span := startSpan(ctx, "PlayerHeroEquipmentsUseCase.Execute", attribute.String("coc.player_tag", playerTag))
+	res, status, err := uc.execute(ctx, playerTag)
+	endSpan(span, status, err)
+	return res, status, err
+}
+
+func (uc *PlayerHeroEquipmentsUseCase) execute(ctx context.Context, playerTag string) (PlayerHeroEquipmentsResult, int, error) {
 	var out PlayerHeroEquipmentsResult
 	// Validate path-safety for tag
 	if _, err := url.PathUnescape(playerTag); err != nil {
@@ -42,6 +51,16 @@ func (uc *PlayerHeroEquipmentsUseCase) Execute(ctx context.Context, playerTag st
 	if err != nil || status >= 400 {
 		return out, status, err
 	}
+	out, err = buildPlayerHeroEquipments(uc.catalog, playerTag, body)
+	if err != nil {
+		return out, 500, err
+	}
+	return out, 200, nil
+}
+
+// buildPlayerHeroEquipments lists available and catalog-only equipment from a raw player payload.
+func buildPlayerHeroEquipments(catalog ports.CatalogRepository, playerTag string, body []byte) (PlayerHeroEquipmentsResult, error) {
+	var out PlayerHeroEquipmentsResult
 	type equipment struct {
 		Name     json.RawMessage `json:"name"`
 		Level    int             `json:"level"`
@@ -51,7 +70,7 @@ func (uc *PlayerHeroEquipmentsUseCase) Execute(ctx context.Context, playerTag st
 		HeroEquipment []equipment `json:"heroEquipment"`
 	}
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return out, 500, err
+		return out, err
 	}
 	available := make([]Equipment, 0, len(resp.HeroEquipment))
 	seen := map[string]struct{}{}
@@ -66,19 +85,19 @@ func (uc *PlayerHeroEquipmentsUseCase) Execute(ctx context.Context, playerTag st
 			Level:     it.Level,
 			MaxLevel:  it.MaxLevel,
 			Available: true,
-			ID:        uc.catalog.GetID(name),
+			ID:        catalog.GetID(name),
 		})
 	}
 	unavailable := make([]Equipment, 0)
 	// catalog-only names are considered unavailable
-	for _, name := range uc.catalog.ListEquipmentNames() {
+	for _, name := range catalog.ListEquipmentNames() {
 		if _, ok := seen[name]; !ok {
 			unavailable = append(unavailable, Equipment{
 				Name:      name,
 				Level:     0,
 				MaxLevel:  0,
 				Available: false,
-				ID:        uc.catalog.GetID(name),
+				ID:        catalog.GetID(name),
 			})
 		}
 	}
@@ -97,5 +116,5 @@ func (uc *PlayerHeroEquipmentsUseCase) Execute(ctx context.Context, playerTag st
 	out.PlayerTag = playerTag
 	out.Available = available
 	out.Unavailable = unavailable
-	return out, 200, nil
+	return out, nil
 }
@@ -0,0 +1,148 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
+	"github.com/ab-dauletkhan/coc/internal/domain/ports"
+	"github.com/ab-dauletkhan/coc/internal/logging"
+)
+
+// SnapshotRetention limits how much snapshot history is kept per player.
+// Zero values keep everything.
+type SnapshotRetention struct {
+	MaxAge       time.Duration
+	MaxPerPlayer int
+}
+
+// SnapshotRecorder is a ports.PlayerAPI decorator that stores a snapshot of every
+// player payload successfully fetched through it, publishing equipment unlocks and
+// upgrades found against the previous snapshot. Recording failures are logged and
+// never affect the caller.
+type SnapshotRecorder struct {
+	playerAPI ports.PlayerAPI
+	snapshots ports.SnapshotRepository
+	publisher ports.EventPublisher
+	retention SnapshotRetention
+	now       func() time.Time
+}
+
+// NewSnapshotRecorder returns a recorder; publisher may be nil to skip publishing events.
+func NewSnapshotRecorder(playerAPI ports.PlayerAPI, snapshots ports.SnapshotRepository, publisher ports.EventPublisher, retention SnapshotRetention) *SnapshotRecorder {
+	return &SnapshotRecorder{playerAPI: playerAPI, snapshots: snapshots, publisher: publisher, retention: retention, now: time.Now}
+}
+
+func (r *SnapshotRecorder) GetPlayerRaw(ctx context.Context, tag string) ([]byte, int, error) {
+	b, status, err := r.playerAPI.GetPlayerRaw(ctx, tag)
+	if err == nil && status == 200 {
+		if rerr := r.record(ctx, tag, b); rerr != nil {
+			logging.FromContext(ctx).Warn("failed to record snapshot", "player_tag", canonicalTag(tag), "error", rerr)
+		}
+	}
+	return b, status, err
+}
+
+// Prune applies the retention settings to every player's history.
+func (r *SnapshotRecorder) Prune(ctx context.Context) (int, error) {
+	return r.snapshots.PruneSnapshots(ctx, "", r.cutoff(), r.retention.MaxPerPlayer)
+}
+
+func (r *SnapshotRecorder) record(ctx context.Context, tag string, body []byte) error {
+	snap, err := parsePlayerSnapshot(body)
+	if err != nil {
+		return err
+	}
+	if snap.PlayerTag == "" {
+		snap.PlayerTag = canonicalTag(tag)
+	}
+	snap.TakenAt = r.now().UTC()
+	snap.LastSeenAt = snap.TakenAt
+	// The diff is taken against the predecessor the store saw when inserting, so two
+	// concurrent fetches of the same levels publish their events once.
+	prev, hadPrev, stored, err := r.snapshots.SaveSnapshot(ctx, snap)
+	if err != nil || !stored {
+		return err
+	}
+	// The first snapshot of a player is only a baseline.
+	if hadPrev && r.publisher != nil {
+		for _, e := range equipmentEvents(prev, snap) {
+			r.publisher.Publish(ctx, e)
+		}
+	}
+	_, err = r.snapshots.PruneSnapshots(ctx, snap.PlayerTag, r.cutoff(), r.retention.MaxPerPlayer)
+	return err
+}
+
+func (r *SnapshotRecorder) cutoff() time.Time {
+	if r.retention.MaxAge <= 0 {
+		return time.Time{}
+	}
+	return r.now().Add(-r.retention.MaxAge)
+}
+
+// equipmentEvents lists the unlocks and upgrades between two snapshots of a player.
+func equipmentEvents(prev, cur models.PlayerSnapshot) []models.Event {
+	before := equipmentLevels(prev)
+	var out []models.Event
+	for _, e := range cur.Equipment {
+		old := before[strings.ToUpper(e.Name)]
+		if e.Level <= old {
+			continue
+		}
+		t := models.EventEquipmentUpgraded
+		if old == 0 {
+			t = models.EventEquipmentUnlocked
+		}
+		out = append(out, models.Event{
+			ID:         newID(),
+			Type:       t,
+			OccurredAt: cur.TakenAt,
+			Data: map[string]any{
+				"playerTag": cur.PlayerTag,
+				"equipment": e.Name,
+				"fromLevel": old,
+				"toLevel":   e.Level,
+				"maxLevel":  e.MaxLevel,
+			},
+		})
+	}
+	return out
+}
+
+// parsePlayerSnapshot normalizes the parts of a raw player payload we keep history of.
+// Only home village heroes are kept since hero equipment belongs to them.
+func parsePlayerSnapshot(body []byte) (models.PlayerSnapshot, error) {
+	var p struct {
+		Tag           string `json:"tag"`
+		TownHallLevel int    `json:"townHallLevel"`
+		Heroes        []struct {
+			Name     string `json:"name"`
+			Level    int    `json:"level"`
+			MaxLevel int    `json:"maxLevel"`
+			Village  string `json:"village"`
+		} `json:"heroes"`
+	}
+	if err := json.Unmarshal(body, &p); err != nil {
+		return models.PlayerSnapshot{}, err
+	}
+	equipment, err := parseHeroEquipment(body)
+	if err != nil {
+		return models.PlayerSnapshot{}, err
+	}
+	snap := models.PlayerSnapshot{
+		PlayerTag: canonicalTag(p.Tag),
+		TownHall:  p.TownHallLevel,
+		Equipment: equipment,
+	}
+	for _, h := range p.Heroes {
+		if h.Village != "" && h.Village != "home" {
+			continue
+		}
+		snap.Heroes = append(snap.Heroes, models.HeroLevel{Name: h.Name, Level: h.Level, MaxLevel: h.MaxLevel})
+	}
+	snap.Normalize()
+	return snap, nil
+}
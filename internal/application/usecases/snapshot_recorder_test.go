@@ -0,0 +1,67 @@
+package usecases_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/ab-dauletkhan/coc/internal/application/usecases"
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
+)
+
+// stubPlayerAPI serves a fixed player payload.
+type stubPlayerAPI struct {
+	mu   sync.Mutex
+	body []byte
+}
+
+func (s *stubPlayerAPI) set(level int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.body = fmt.Appendf(nil, `{"tag":"#P1","townHallLevel":16,"heroEquipment":[{"name":"Giant Gauntlet","level":%d,"maxLevel":27}]}`, level)
+}
+
+func (s *stubPlayerAPI) GetPlayerRaw(ctx context.Context, tag string) ([]byte, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.body, 200, nil
+}
+
+type collectingPublisher struct {
+	mu     sync.Mutex
+	events []models.Event
+}
+
+func (p *collectingPublisher) Publish(ctx context.Context, e models.Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, e)
+}
+
+func TestSnapshotRecorderPublishesConcurrentUpgradeOnce(t *testing.T) {
+	store := newWebhookTestStore(t)
+	api := &stubPlayerAPI{}
+	pub := &collectingPublisher{}
+	rec := usecases.NewSnapshotRecorder(api, store, pub, usecases.SnapshotRetention{})
+
+	api.set(10)
+	if _, _, err := rec.GetPlayerRaw(context.Background(), "#P1"); err != nil {
+		t.Fatalf("baseline fetch: %v", err)
+	}
+	api.set(11)
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Go(func() {
+			rec.GetPlayerRaw(context.Background(), "#P1")
+		})
+	}
+	wg.Wait()
+
+	if len(pub.events) != 1 {
+		t.Fatalf("published %d events, want 1: %+v", len(pub.events), pub.events)
+	}
+	if e := pub.events[0]; e.Type != models.EventEquipmentUpgraded || e.Data["toLevel"] != 11 {
+		t.Errorf("event = %s %v, want an upgrade to level 11", e.Type, e.Data)
+	}
+}
@@ -0,0 +1,33 @@
+package usecases
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/ab-dauletkhan/coc/internal/application/usecases")
+
+// startSpan starts the span of a use case call, named "<UseCase>.<Method>".
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan records the status and error returned by a use case call and ends its span.
+// Only errors without a client status (4xx) mark the span as failed.
+func endSpan(span trace.Span, status int, err error) {
+	span.SetAttributes(attribute.Int("app.status", status))
+	if err != nil {
+		span.RecordError(err)
+		if status < 400 || status >= 500 {
+			span.SetStatus(codes.Error, err.Error())
+		}
+	} else if status >= 500 {
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
+	span.End()
+}
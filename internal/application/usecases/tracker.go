@@ -0,0 +1,325 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
+	"github.com/ab-dauletkhan/coc/internal/domain/ports"
+	"github.com/ab-dauletkhan/coc/internal/logging"
+)
+
+// TrackerSettings configures the background tracker.
+type TrackerSettings struct {
+	// Tick is how often due targets are looked for.
+	Tick time.Duration
+	// Concurrency bounds how many targets are polled at once.
+	Concurrency int
+	// DefaultInterval applies when a target is registered without an interval.
+	DefaultInterval time.Duration
+	// MinInterval is the shortest poll interval a target may use.
+	MinInterval time.Duration
+}
+
+// Tracker periodically polls registered players and clans. Player payloads are
+// fetched through the configured ports.PlayerAPI (normally the SnapshotRecorder), so
+// every poll adds to the snapshot history; clan member lists are passed to the
+// membership use case to detect membership changes.
+type Tracker struct {
+	targets    ports.TrackerRepository
+	clanAPI    ports.ClanAPI
+	playerAPI  ports.PlayerAPI
+	membership *ClanMembershipUseCase
+	fanOut     *FanOut
+	settings   TrackerSettings
+	now        func() time.Time
+
+	mu        sync.Mutex
+	running   map[string]struct{}
+	started   bool
+	lastTick  time.Time
+	lastError string
+}
+
+func NewTracker(targets ports.TrackerRepository, clanAPI ports.ClanAPI, playerAPI ports.PlayerAPI, membership *ClanMembershipUseCase, fanOut *FanOut, settings TrackerSettings) *Tracker {
+	if settings.Tick <= 0 {
+		settings.Tick = 5 * time.Second
+	}
+	if settings.Concurrency <= 0 {
+		settings.Concurrency = 1
+	}
+	if settings.DefaultInterval <= 0 {
+		settings.DefaultInterval = time.Hour
+	}
+	return &Tracker{
+		targets:    targets,
+		clanAPI:    clanAPI,
+		playerAPI:  playerAPI,
+		membership: membership,
+		fanOut:     fanOut,
+		settings:   settings,
+		now:        time.Now,
+		running:    map[string]struct{}{},
+	}
+}
+
+// ErrNotTracked is returned (with status 404) when unregistering an unknown target.
+var ErrNotTracked = errors.New("target is not tracked")
+
+type TrackedTargetStatus struct {
+	Kind              models.TrackedKind `json:"kind"`
+	Tag               string             `json:"tag"`
+	Interval          string             `json:"interval"`
+	CreatedAt         time.Time          `json:"createdAt"`
+	NextRunAt         time.Time          `json:"nextRunAt"`
+	Running           bool               `json:"running"`
+	LastRunAt         *time.Time         `json:"lastRunAt,omitempty"`
+	LastSuccessAt     *time.Time         `json:"lastSuccessAt,omitempty"`
+	LastError         string             `json:"lastError,omitempty"`
+	LastFailedMembers int                `json:"lastFailedMembers"`
+	Runs              int                `json:"runs"`
+	Failures          int                `json:"failures"`
+}
+
+type TrackerStatusResult struct {
+	Started   bool                  `json:"started"`
+	LastTick  *time.Time            `json:"lastTick,omitempty"`
+	LastError string                `json:"lastError,omitempty"`
+	Targets   []TrackedTargetStatus `json:"targets"`
+}
+
+// Register starts tracking a player or clan. A zero interval selects the default.
+// Registering an already tracked target updates its interval.
+func (t *Tracker) Register(ctx context.Context, kind models.TrackedKind, tag string, interval time.Duration) (TrackedTargetStatus, int, error) {
+	if kind != models.TrackedPlayer && kind != models.TrackedClan {
+		return TrackedTargetStatus{}, 400, fmt.Errorf("%w: unknown target kind %q", ErrInvalidQuery, kind)
+	}
+	tag = canonicalTag(tag)
+	if tag == "" {
+		return TrackedTargetStatus{}, 400, fmt.Errorf("%w: missing tag", ErrInvalidQuery)
+	}
+	if interval == 0 {
+		interval = t.settings.DefaultInterval
+	}
+	if interval < t.settings.MinInterval {
+		return TrackedTargetStatus{}, 400, fmt.Errorf("%w: interval must be at least %s", ErrInvalidQuery, t.settings.MinInterval)
+	}
+	target := models.TrackedTarget{Kind: kind, Tag: tag, Interval: interval, CreatedAt: t.now().UTC()}
+	if err := t.targets.SaveTrackedTarget(ctx, target); err != nil {
+		return TrackedTargetStatus{}, 500, err
+	}
+	all, err := t.targets.ListTrackedTargets(ctx)
+	if err != nil {
+		return TrackedTargetStatus{}, 500, err
+	}
+	for _, tt := range all {
+		if tt.Kind == kind && tt.Tag == tag {
+			return t.targetStatus(tt), 200, nil
+		}
+	}
+	return t.targetStatus(target), 200, nil
+}
+
+func (t *Tracker) Unregister(ctx context.Context, kind models.TrackedKind, tag string) (int, error) {
+	ok, err := t.targets.DeleteTrackedTarget(ctx, kind, canonicalTag(tag))
+	if err != nil {
+		return 500, err
+	}
+	if !ok {
+		return 404, ErrNotTracked
+	}
+	return 204, nil
+}
+
+// Status reports the tracker loop state and every target's last run.
+func (t *Tracker) Status(ctx context.Context) (TrackerStatusResult, int, error) {
+	all, err := t.targets.ListTrackedTargets(ctx)
+	if err != nil {
+		return TrackerStatusResult{}, 500, err
+	}
+	t.mu.Lock()
+	out := TrackerStatusResult{Started: t.started, LastError: t.lastError, Targets: make([]TrackedTargetStatus, 0, len(all))}
+	if !t.lastTick.IsZero() {
+		tick := t.lastTick
+		out.LastTick = &tick
+	}
+	t.mu.Unlock()
+	for _, tt := range all {
+		out.Targets = append(out.Targets, t.targetStatus(tt))
+	}
+	return out, 200, nil
+}
+
+// Run polls due targets until ctx is cancelled, then waits for in-flight polls, which
+// are not cancelled with ctx but bounded by their own timeouts.
+func (t *Tracker) Run(ctx context.Context) {
+	t.mu.Lock()
+	t.started = true
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		t.started = false
+		t.mu.Unlock()
+	}()
+
+	sem := make(chan struct{}, t.settings.Concurrency)
+	wg := sync.WaitGroup{}
+	defer wg.Wait()
+
+	ticker := time.NewTicker(t.settings.Tick)
+	defer ticker.Stop()
+	for {
+		for _, target := range t.dueTargets(ctx) {
+			select {
+			case <-ctx.Done():
+				return
+			case sem <- struct{}{}:
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				defer t.release(target)
+				t.poll(context.WithoutCancel(ctx), target)
+			}()
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dueTargets returns targets whose next run is due and claims them as running.
+func (t *Tracker) dueTargets(ctx context.Context) []models.TrackedTarget {
+	all, err := t.targets.ListTrackedTargets(ctx)
+	now := t.now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lastTick = now.UTC()
+	if err != nil {
+		t.lastError = err.Error()
+		return nil
+	}
+	t.lastError = ""
+	var due []models.TrackedTarget
+	for _, tt := range all {
+		key := trackedKey(tt)
+		if _, busy := t.running[key]; busy || tt.NextRunAt().After(now) {
+			continue
+		}
+		t.running[key] = struct{}{}
+		due = append(due, tt)
+	}
+	return due
+}
+
+func (t *Tracker) release(target models.TrackedTarget) {
+	t.mu.Lock()
+	delete(t.running, trackedKey(target))
+	t.mu.Unlock()
+}
+
+func (t *Tracker) poll(ctx context.Context, target models.TrackedTarget) {
+	ctx, span := startSpan(ctx, "Tracker.poll", attribute.String("app.target_kind", string(target.Kind)), attribute.String("coc.tag", target.Tag))
+	defer span.End()
+	run := target.Status
+	run.LastRunAt = t.now().UTC()
+	run.Runs++
+
+	var err error
+	switch target.Kind {
+	case models.TrackedPlayer:
+		err = t.pollPlayer(ctx, target.Tag)
+	case models.TrackedClan:
+		run.LastFailedMembers, err = t.pollClan(ctx, target.Tag)
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		run.Failures++
+		run.LastError = err.Error()
+	} else {
+		run.LastSuccessAt = t.now().UTC()
+		run.LastError = ""
+	}
+	// Record with a fresh context so a shutdown does not lose the outcome of a finished poll.
+	if rerr := t.targets.RecordTrackerRun(context.WithoutCancel(ctx), target.Kind, target.Tag, run); rerr != nil {
+		logging.FromContext(ctx).Warn("failed to record tracker run", "target_kind", target.Kind, "tag", target.Tag, "error", rerr)
+	}
+}
+
+func (t *Tracker) pollPlayer(ctx context.Context, tag string) error {
+	ctxp, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	_, status, err := t.playerAPI.GetPlayerRaw(ctxp, normalizePlayerTag(tag))
+	if err != nil {
+		return err
+	}
+	if status >= 400 {
+		return fmt.Errorf("upstream status %d", status)
+	}
+	return nil
+}
+
+// pollClan fetches the member list and every member, returning how many members failed.
+func (t *Tracker) pollClan(ctx context.Context, tag string) (int, error) {
+	ctxc, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	members, status, err := fetchClanMembers(ctxc, t.clanAPI, normalizePlayerTag(tag))
+	if err != nil {
+		return 0, err
+	}
+	if status >= 400 {
+		return 0, fmt.Errorf("upstream status %d", status)
+	}
+	var observeErr error
+	if t.membership != nil {
+		if _, err := t.membership.Observe(ctxc, tag, members); err != nil {
+			observeErr = fmt.Errorf("record membership changes: %w", err)
+		}
+	}
+	failed := 0
+	for _, f := range t.fanOut.fetchMemberPlayers(ctxc, t.playerAPI, members) {
+		if f.Status != MemberStatusOK {
+			failed++
+		}
+	}
+	return failed, observeErr
+}
+
+func (t *Tracker) targetStatus(tt models.TrackedTarget) TrackedTargetStatus {
+	t.mu.Lock()
+	_, running := t.running[trackedKey(tt)]
+	t.mu.Unlock()
+	out := TrackedTargetStatus{
+		Kind:              tt.Kind,
+		Tag:               tt.Tag,
+		Interval:          tt.Interval.String(),
+		CreatedAt:         tt.CreatedAt,
+		NextRunAt:         tt.NextRunAt(),
+		Running:           running,
+		LastError:         tt.Status.LastError,
+		LastFailedMembers: tt.Status.LastFailedMembers,
+		Runs:              tt.Status.Runs,
+		Failures:          tt.Status.Failures,
+	}
+	if !tt.Status.LastRunAt.IsZero() {
+		at := tt.Status.LastRunAt
+		out.LastRunAt = &at
+	}
+	if !tt.Status.LastSuccessAt.IsZero() {
+		at := tt.Status.LastSuccessAt
+		out.LastSuccessAt = &at
+	}
+	return out
+}
+
+func trackedKey(tt models.TrackedTarget) string { return string(tt.Kind) + ":" + tt.Tag }
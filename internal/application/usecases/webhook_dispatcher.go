@@ -0,0 +1,199 @@
+package usecases
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
+	"github.com/ab-dauletkhan/coc/internal/domain/ports"
+	"github.com/ab-dauletkhan/coc/internal/logging"
+)
+
+// WebhookSettings configures webhook delivery.
+type WebhookSettings struct {
+	// MaxAttempts bounds delivery attempts per event and subscription.
+	MaxAttempts int
+	// Backoff is the wait before the first retry; it doubles per retry up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Concurrency bounds how many requests are sent at once.
+	Concurrency int
+	// QueueSize bounds events waiting for delivery; events beyond it are dropped.
+	QueueSize int
+}
+
+// Webhook request headers. The signature is the hex HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with the subscription secret, prefixed with "sha256=".
+const (
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookIDHeader        = "X-Webhook-Id"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// WebhookDispatcher is a ports.EventPublisher delivering events to matching webhook
+// subscriptions. Publish only queues the event; Run delivers queued events, retrying
+// failed deliveries with exponential backoff and logging every attempt.
+type WebhookDispatcher struct {
+	webhooks ports.WebhookRepository
+	sender   ports.WebhookSender
+	settings WebhookSettings
+	queue    chan models.Event
+	now      func() time.Time
+}
+
+func NewWebhookDispatcher(webhooks ports.WebhookRepository, sender ports.WebhookSender, settings WebhookSettings) *WebhookDispatcher {
+	if settings.MaxAttempts <= 0 {
+		settings.MaxAttempts = 1
+	}
+	if settings.Backoff <= 0 {
+		settings.Backoff = time.Second
+	}
+	if settings.MaxBackoff < settings.Backoff {
+		settings.MaxBackoff = settings.Backoff
+	}
+	if settings.Concurrency <= 0 {
+		settings.Concurrency = 1
+	}
+	if settings.QueueSize <= 0 {
+		settings.QueueSize = 1000
+	}
+	return &WebhookDispatcher{
+		webhooks: webhooks,
+		sender:   sender,
+		settings: settings,
+		queue:    make(chan models.Event, settings.QueueSize),
+		now:      time.Now,
+	}
+}
+
+// SignWebhookPayload returns the signature header value for a payload sent at timestamp.
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (d *WebhookDispatcher) Publish(ctx context.Context, event models.Event) {
+	select {
+	case d.queue <- event:
+	default:
+		logging.FromContext(ctx).Warn("webhook queue is full; dropping event", "event_type", event.Type, "event_id", event.ID)
+	}
+}
+
+// Run delivers queued events until ctx is cancelled, then waits for in-flight deliveries.
+// Pending retries are abandoned on cancellation.
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	sem := make(chan struct{}, d.settings.Concurrency)
+	wg := sync.WaitGroup{}
+	defer wg.Wait()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-d.queue:
+			subs, err := d.webhooks.ListWebhooks(ctx)
+			if err != nil {
+				logging.FromContext(ctx).Warn("failed to list webhooks", "event_type", event.Type, "event_id", event.ID, "error", err)
+				continue
+			}
+			body, err := json.Marshal(toPublishedEvent(event))
+			if err != nil {
+				logging.FromContext(ctx).Warn("failed to encode event", "event_type", event.Type, "event_id", event.ID, "error", err)
+				continue
+			}
+			for _, sub := range subs {
+				if !sub.Wants(event.Type) {
+					continue
+				}
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					d.deliver(ctx, sem, sub, event, body)
+				}()
+			}
+		}
+	}
+}
+
+// deliver sends body to the subscription until it is accepted, fails permanently, or
+// the attempts are used up. Only sending holds a concurrency slot, not the backoff wait.
+func (d *WebhookDispatcher) deliver(ctx context.Context, sem chan struct{}, sub models.WebhookSubscription, event models.Event, body []byte) {
+	backoff := d.settings.Backoff
+	for attempt := 1; attempt <= d.settings.MaxAttempts; attempt++ {
+		select {
+		case <-ctx.Done():
+			return
+		case sem <- struct{}{}:
+		}
+		// A started attempt is finished on shutdown; it is bounded by the sender's timeout.
+		rec := d.attempt(context.WithoutCancel(ctx), sub, event, body, attempt)
+		<-sem
+		// Log with a fresh context so a shutdown does not lose the outcome of a finished attempt.
+		if err := d.webhooks.AppendWebhookDelivery(context.WithoutCancel(ctx), rec); err != nil {
+			logging.FromContext(ctx).Warn("failed to record webhook delivery", "delivery_id", rec.ID, "webhook_id", sub.ID, "error", err)
+		}
+		if rec.Success || !retryableDelivery(rec) || attempt == d.settings.MaxAttempts {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, d.settings.MaxBackoff)
+	}
+}
+
+func (d *WebhookDispatcher) attempt(ctx context.Context, sub models.WebhookSubscription, event models.Event, body []byte, attempt int) models.WebhookDelivery {
+	start := d.now()
+	ts := start.Unix()
+	headers := map[string]string{
+		"Content-Type":         "application/json",
+		"User-Agent":           "coc-webhooks/1",
+		WebhookEventHeader:     string(event.Type),
+		WebhookIDHeader:        event.ID,
+		WebhookTimestampHeader: strconv.FormatInt(ts, 10),
+		WebhookSignatureHeader: SignWebhookPayload(sub.Secret, ts, body),
+	}
+	status, err := d.sender.Send(ctx, sub.URL, headers, body)
+	rec := models.WebhookDelivery{
+		ID:             newID(),
+		SubscriptionID: sub.ID,
+		EventID:        event.ID,
+		EventType:      event.Type,
+		Attempt:        attempt,
+		AttemptedAt:    start.UTC(),
+		Duration:       d.now().Sub(start),
+		StatusCode:     status,
+		Success:        err == nil && status >= 200 && status < 300,
+	}
+	switch {
+	case err != nil:
+		rec.Error = err.Error()
+	case !rec.Success:
+		rec.Error = fmt.Sprintf("receiver responded with status %d", status)
+	}
+	return rec
+}
+
+// retryableDelivery reports whether a failed attempt may succeed later: transport
+// errors, timeouts, throttling, and server errors are retried; other client errors are not.
+func retryableDelivery(rec models.WebhookDelivery) bool {
+	switch {
+	case rec.StatusCode == 0, rec.StatusCode == 408, rec.StatusCode == 429:
+		return true
+	default:
+		return rec.StatusCode >= 500
+	}
+}
@@ -0,0 +1,190 @@
+package usecases_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	secondary "github.com/ab-dauletkhan/coc/internal/adapters/secondary"
+	"github.com/ab-dauletkhan/coc/internal/application/usecases"
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
+)
+
+func newWebhookTestStore(t *testing.T) *secondary.FileStore {
+	t.Helper()
+	store, err := secondary.NewFileStore(filepath.Join(t.TempDir(), "store.json"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func startDispatcher(t *testing.T, store *secondary.FileStore) *usecases.WebhookDispatcher {
+	t.Helper()
+	d := usecases.NewWebhookDispatcher(store, secondary.NewHTTPWebhookSender(time.Second, true), usecases.WebhookSettings{
+		MaxAttempts: 4,
+		Backoff:     10 * time.Millisecond,
+		MaxBackoff:  20 * time.Millisecond,
+		Concurrency: 2,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		d.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return d
+}
+
+// waitForDeliveries polls the delivery log until it holds n attempts.
+func waitForDeliveries(t *testing.T, store *secondary.FileStore, id string, n int) []models.WebhookDelivery {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got, err := store.ListWebhookDeliveries(context.Background(), id, 0)
+		if err != nil {
+			t.Fatalf("list deliveries: %v", err)
+		}
+		if len(got) >= n {
+			return got
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d deliveries, want %d", len(got), n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWebhookDispatcherDeliversSignedMatchingEvents(t *testing.T) {
+	type received struct {
+		header http.Header
+		body   []byte
+	}
+	got := make(chan received, 4)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		got <- received{header: r.Header.Clone(), body: b}
+	}))
+	defer srv.Close()
+
+	store := newWebhookTestStore(t)
+	webhooks := usecases.NewWebhookUseCase(store, true)
+	sub, status, err := webhooks.Create(context.Background(), srv.URL, "s3cret", []string{"equipment.upgraded"})
+	if err != nil || status != 201 {
+		t.Fatalf("create webhook: status %d, err %v", status, err)
+	}
+	d := startDispatcher(t, store)
+
+	d.Publish(context.Background(), models.Event{ID: "e1", Type: models.EventClanMemberJoined, OccurredAt: time.Now()})
+	d.Publish(context.Background(), models.Event{
+		ID:         "e2",
+		Type:       models.EventEquipmentUpgraded,
+		OccurredAt: time.Now(),
+		Data:       map[string]any{"equipment": "Giant Gauntlet", "fromLevel": 1, "toLevel": 2},
+	})
+
+	var req received
+	select {
+	case req = <-got:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no webhook request received")
+	}
+	if ev := req.header.Get(usecases.WebhookEventHeader); ev != "equipment.upgraded" {
+		t.Fatalf("event header = %q, want equipment.upgraded", ev)
+	}
+	ts, err := strconv.ParseInt(req.header.Get(usecases.WebhookTimestampHeader), 10, 64)
+	if err != nil {
+		t.Fatalf("timestamp header: %v", err)
+	}
+	if sig, want := req.header.Get(usecases.WebhookSignatureHeader), usecases.SignWebhookPayload("s3cret", ts, req.body); sig != want {
+		t.Fatalf("signature = %q, want %q", sig, want)
+	}
+	var payload struct {
+		ID   string         `json:"id"`
+		Type string         `json:"type"`
+		Data map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(req.body, &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if payload.ID != "e2" || payload.Data["equipment"] != "Giant Gauntlet" {
+		t.Fatalf("unexpected payload %s", req.body)
+	}
+
+	deliveries := waitForDeliveries(t, store, sub.ID, 1)
+	if len(deliveries) != 1 || !deliveries[0].Success || deliveries[0].StatusCode != 200 {
+		t.Fatalf("unexpected delivery log %+v", deliveries)
+	}
+	select {
+	case extra := <-got:
+		t.Fatalf("unsubscribed event delivered: %s", extra.body)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestWebhookDispatcherRetriesWithBackoff(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	store := newWebhookTestStore(t)
+	sub, _, err := usecases.NewWebhookUseCase(store, true).Create(context.Background(), srv.URL, "", []string{"*"})
+	if err != nil {
+		t.Fatalf("create webhook: %v", err)
+	}
+	if sub.Secret == "" {
+		t.Fatal("expected a generated secret")
+	}
+	d := startDispatcher(t, store)
+	d.Publish(context.Background(), models.Event{ID: "e1", Type: models.EventEquipmentUnlocked, OccurredAt: time.Now()})
+
+	deliveries := waitForDeliveries(t, store, sub.ID, 3)
+	// Newest first: two failed attempts followed by a success.
+	if !deliveries[0].Success || deliveries[0].Attempt != 3 {
+		t.Fatalf("last attempt = %+v, want successful attempt 3", deliveries[0])
+	}
+	for _, failed := range deliveries[1:] {
+		if failed.Success || failed.StatusCode != http.StatusServiceUnavailable || failed.Error == "" {
+			t.Fatalf("unexpected failed attempt %+v", failed)
+		}
+	}
+}
+
+func TestWebhookDispatcherDoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer srv.Close()
+
+	store := newWebhookTestStore(t)
+	sub, _, err := usecases.NewWebhookUseCase(store, true).Create(context.Background(), srv.URL, "", []string{"*"})
+	if err != nil {
+		t.Fatalf("create webhook: %v", err)
+	}
+	d := startDispatcher(t, store)
+	d.Publish(context.Background(), models.Event{ID: "e1", Type: models.EventClanMemberLeft, OccurredAt: time.Now()})
+
+	waitForDeliveries(t, store, sub.ID, 1)
+	time.Sleep(100 * time.Millisecond)
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("receiver called %d times, want 1", n)
+	}
+}
@@ -0,0 +1,180 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
+	"github.com/ab-dauletkhan/coc/internal/domain/ports"
+	"github.com/ab-dauletkhan/coc/internal/netguard"
+)
+
+// WebhookUseCase manages webhook subscriptions and exposes their delivery log.
+type WebhookUseCase struct {
+	webhooks     ports.WebhookRepository
+	allowPrivate bool
+	now          func() time.Time
+}
+
+// NewWebhookUseCase returns a use case rejecting URLs whose host is a loopback,
+// private, or link-local IP or a localhost name, unless allowPrivate is set. Other
+// names are checked by the sender when dialing.
+func NewWebhookUseCase(webhooks ports.WebhookRepository, allowPrivate bool) *WebhookUseCase {
+	return &WebhookUseCase{webhooks: webhooks, allowPrivate: allowPrivate, now: time.Now}
+}
+
+// ErrWebhookNotFound is returned (with status 404) for an unknown subscription ID.
+var ErrWebhookNotFound = errors.New("webhook not found")
+
+const (
+	defaultWebhookDeliveries = 50
+	maxWebhookDeliveries     = 200
+)
+
+type Webhook struct {
+	ID     string             `json:"id"`
+	URL    string             `json:"url"`
+	Events []models.EventType `json:"events"`
+	// Secret is only returned when the subscription is created.
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type WebhookDelivery struct {
+	ID          string           `json:"id"`
+	EventID     string           `json:"eventId"`
+	EventType   models.EventType `json:"eventType"`
+	Attempt     int              `json:"attempt"`
+	AttemptedAt time.Time        `json:"attemptedAt"`
+	DurationMs  int64            `json:"durationMs"`
+	StatusCode  int              `json:"statusCode,omitempty"`
+	Error       string           `json:"error,omitempty"`
+	Success     bool             `json:"success"`
+}
+
+type WebhookDeliveriesResult struct {
+	WebhookID  string            `json:"webhookId"`
+	Deliveries []WebhookDelivery `json:"deliveries"`
+}
+
+// Create subscribes rawURL to the given event types ("*" selects all). A secret is
+// generated when none is given.
+func (uc *WebhookUseCase) Create(ctx context.Context, rawURL, secret string, events []string) (Webhook, int, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Webhook{}, 400, fmt.Errorf("%w: url must be an absolute http(s) URL", ErrInvalidQuery)
+	}
+	if !uc.allowPrivate {
+		if err := netguard.CheckHost(u.Hostname()); err != nil {
+			return Webhook{}, 400, fmt.Errorf("%w: url: %v", ErrInvalidQuery, err)
+		}
+	}
+	if len(events) == 0 {
+		return Webhook{}, 400, fmt.Errorf("%w: at least one event type is required", ErrInvalidQuery)
+	}
+	var types []models.EventType
+	for _, e := range events {
+		et := models.EventType(strings.TrimSpace(e))
+		if et != "*" && !slices.Contains(models.EventTypes, et) {
+			return Webhook{}, 400, fmt.Errorf("%w: unknown event type %q", ErrInvalidQuery, e)
+		}
+		if !slices.Contains(types, et) {
+			types = append(types, et)
+		}
+	}
+	secret = strings.TrimSpace(secret)
+	if secret == "" {
+		secret = newID() + newID()
+	}
+	sub := models.WebhookSubscription{
+		ID:         newID(),
+		URL:        u.String(),
+		Secret:     secret,
+		EventTypes: types,
+		CreatedAt:  uc.now().UTC(),
+	}
+	if err := uc.webhooks.SaveWebhook(ctx, sub); err != nil {
+		return Webhook{}, 500, err
+	}
+	out := toWebhook(sub)
+	out.Secret = sub.Secret
+	return out, 201, nil
+}
+
+func (uc *WebhookUseCase) List(ctx context.Context) ([]Webhook, int, error) {
+	subs, err := uc.webhooks.ListWebhooks(ctx)
+	if err != nil {
+		return nil, 500, err
+	}
+	out := make([]Webhook, len(subs))
+	for i, s := range subs {
+		out[i] = toWebhook(s)
+	}
+	return out, 200, nil
+}
+
+func (uc *WebhookUseCase) Get(ctx context.Context, id string) (Webhook, int, error) {
+	sub, ok, err := uc.webhooks.GetWebhook(ctx, id)
+	if err != nil {
+		return Webhook{}, 500, err
+	}
+	if !ok {
+		return Webhook{}, 404, ErrWebhookNotFound
+	}
+	return toWebhook(sub), 200, nil
+}
+
+func (uc *WebhookUseCase) Delete(ctx context.Context, id string) (int, error) {
+	ok, err := uc.webhooks.DeleteWebhook(ctx, id)
+	if err != nil {
+		return 500, err
+	}
+	if !ok {
+		return 404, ErrWebhookNotFound
+	}
+	return 204, nil
+}
+
+// Deliveries returns the most recent delivery attempts of a subscription, newest
+// first. A zero limit selects the default of 50.
+func (uc *WebhookUseCase) Deliveries(ctx context.Context, id string, limit int) (WebhookDeliveriesResult, int, error) {
+	out := WebhookDeliveriesResult{WebhookID: id, Deliveries: []WebhookDelivery{}}
+	if limit == 0 {
+		limit = defaultWebhookDeliveries
+	}
+	if limit < 1 || limit > maxWebhookDeliveries {
+		return out, 400, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidQuery, maxWebhookDeliveries)
+	}
+	if _, ok, err := uc.webhooks.GetWebhook(ctx, id); err != nil {
+		return out, 500, err
+	} else if !ok {
+		return out, 404, ErrWebhookNotFound
+	}
+	deliveries, err := uc.webhooks.ListWebhookDeliveries(ctx, id, limit)
+	if err != nil {
+		return out, 500, err
+	}
+	for _, d := range deliveries {
+		out.Deliveries = append(out.Deliveries, WebhookDelivery{
+			ID:          d.ID,
+			EventID:     d.EventID,
+			EventType:   d.EventType,
+			Attempt:     d.Attempt,
+			AttemptedAt: d.AttemptedAt,
+			DurationMs:  d.Duration.Milliseconds(),
+			StatusCode:  d.StatusCode,
+			Error:       d.Error,
+			Success:     d.Success,
+		})
+	}
+	return out, 200, nil
+}
+
+func toWebhook(s models.WebhookSubscription) Webhook {
+	return Webhook{ID: s.ID, URL: s.URL, Events: s.EventTypes, CreatedAt: s.CreatedAt}
+}
@@ -0,0 +1,116 @@
+// Package cachehint carries the cache lifetime of upstream responses from the API
+// client back to the HTTP handler that used them, through the request context, so a
+// computed response can be cached no longer than its freshest-expiring input.
+package cachehint
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type ctxKey struct{}
+
+// Collector accumulates the upstream responses seen while serving one request. It is
+// safe for concurrent use by fan-out workers.
+type Collector struct {
+	mu       sync.Mutex
+	seen     bool
+	uncached bool
+	expires  time.Time
+	modified time.Time
+}
+
+// WithCollector returns a context that collects hints into a new Collector.
+func WithCollector(ctx context.Context) (context.Context, *Collector) {
+	c := &Collector{}
+	return context.WithValue(ctx, ctxKey{}, c), c
+}
+
+// Observe records the headers of an upstream response received at now. A response
+// without a usable max-age makes the whole result uncacheable. It is a no-op when
+// ctx carries no Collector.
+func Observe(ctx context.Context, h http.Header, now time.Time) {
+	c, _ := ctx.Value(ctxKey{}).(*Collector)
+	if c == nil {
+		return
+	}
+	maxAge, ok := freshness(h)
+	generated := now
+	if d, err := http.ParseTime(h.Get("Date")); err == nil {
+		generated = d
+	}
+	if lm, err := http.ParseTime(h.Get("Last-Modified")); err == nil {
+		generated = lm
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seen = true
+	if !ok {
+		c.uncached = true
+	} else if exp := now.Add(maxAge); c.expires.IsZero() || exp.Before(c.expires) {
+		c.expires = exp
+	}
+	if generated.After(c.modified) {
+		c.modified = generated
+	}
+}
+
+// Uncacheable records that a result built in ctx lacks an upstream response, for
+// example because a fetch failed or was skipped, so it must not be cached.
+func Uncacheable(ctx context.Context) {
+	c, _ := ctx.Value(ctxKey{}).(*Collector)
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seen = true
+	c.uncached = true
+}
+
+// MaxAge returns how long a result built from the observed responses stays fresh;
+// ok is false when no upstream response was observed or one was not cacheable.
+func (c *Collector) MaxAge(now time.Time) (time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.seen || c.uncached {
+		return 0, false
+	}
+	return max(c.expires.Sub(now), 0), true
+}
+
+// LastModified returns the newest generation time of the observed responses, or the
+// zero time when none was observed.
+func (c *Collector) LastModified() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.modified
+}
+
+// freshness returns the remaining lifetime given by Cache-Control max-age minus Age.
+// Directives may be separated by commas or, as the Clash of Clans API does, by
+// spaces alone.
+func freshness(h http.Header) (time.Duration, bool) {
+	maxAge := -1
+	for _, d := range strings.FieldsFunc(strings.ToLower(h.Get("Cache-Control")), func(r rune) bool { return r == ',' || r == ' ' }) {
+		switch {
+		case d == "no-store" || d == "no-cache" || d == "private":
+			return 0, false
+		case strings.HasPrefix(d, "max-age="):
+			n, err := strconv.Atoi(strings.TrimPrefix(d, "max-age="))
+			if err != nil || n < 0 {
+				return 0, false
+			}
+			maxAge = n
+		}
+	}
+	if maxAge < 0 {
+		return 0, false
+	}
+	age, _ := strconv.Atoi(h.Get("Age"))
+	return time.Duration(max(maxAge-age, 0)) * time.Second, true
+}
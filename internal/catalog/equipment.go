@@ -1,15 +1,21 @@
 package catalog
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"os"
 )
 
 type EquipmentCatalog struct {
+	// Version optionally names the revision of the catalog file.
+	Version             string      `json:"version,omitempty"`
 	Items               []Equipment `json:"items"`
 	CommonCostsPerLevel []OreCost   `json:"commonCostsPerLevel"`
 	EpicCostsPerLevel   []OreCost   `json:"epicCostsPerLevel"`
+	// Checksum is the SHA-256 of the loaded file, set by LoadEquipmentCatalog.
+	Checksum string `json:"-"`
 }
 
 // Hero is an enum describing which hero an equipment belongs to.
@@ -38,15 +44,15 @@ type OreCost struct {
 
 func LoadEquipmentCatalog(path string) (EquipmentCatalog, error) {
 	var cat EquipmentCatalog
-	f, err := os.Open(path)
+	b, err := os.ReadFile(path)
 	if err != nil {
 		return cat, err
 	}
-	defer f.Close()
-	decoder := json.NewDecoder(f)
-	if err := decoder.Decode(&cat); err != nil {
+	if err := json.Unmarshal(b, &cat); err != nil {
 		return cat, err
 	}
+	sum := sha256.Sum256(b)
+	cat.Checksum = hex.EncodeToString(sum[:])
 	for i := range cat.Items {
 		if cat.Items[i].Name == "" {
 			return cat, errors.New("equipment catalog item missing name")
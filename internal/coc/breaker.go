@@ -0,0 +1,97 @@
+package coc
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without contacting the API while the circuit is open.
+var ErrCircuitOpen = errors.New("upstream circuit is open")
+
+// Circuit states reported by Breaker.State.
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half-open"
+)
+
+// Breaker stops sending requests after threshold consecutive failures (transport
+// errors and 5xx responses) for cooldown, then lets a single trial request through;
+// its outcome closes or reopens the circuit. A nil Breaker or a non-positive
+// threshold never opens.
+type Breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openedAt  time.Time
+	trial     bool
+	now       func() time.Time
+}
+
+func NewBreaker(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+// Allow reports whether a request may be sent now.
+func (b *Breaker) Allow() bool {
+	if b == nil || b.threshold <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.threshold {
+		return true
+	}
+	if b.now().Sub(b.openedAt) < b.cooldown || b.trial {
+		return false
+	}
+	b.trial = true
+	return true
+}
+
+// Record reports the outcome of a request allowed by Allow.
+func (b *Breaker) Record(failed bool) {
+	if b == nil || b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+	if !failed {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openedAt = b.now()
+	}
+}
+
+// Abandon releases a request allowed by Allow that was never sent.
+func (b *Breaker) Abandon() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+}
+
+// State returns CircuitClosed, CircuitOpen, or CircuitHalfOpen when the cooldown has
+// passed and the next request is a trial.
+func (b *Breaker) State() string {
+	if b == nil || b.threshold <= 0 {
+		return CircuitClosed
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case b.failures < b.threshold:
+		return CircuitClosed
+	case b.now().Sub(b.openedAt) < b.cooldown:
+		return CircuitOpen
+	}
+	return CircuitHalfOpen
+}
@@ -0,0 +1,123 @@
+package coc
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeClock is advanced by the test.
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func newTestBreaker(threshold int, cooldown time.Duration) (*Breaker, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	b := NewBreaker(threshold, cooldown)
+	b.now = clock.now
+	return b, clock
+}
+
+func TestBreakerTransitions(t *testing.T) {
+	type step struct {
+		name      string
+		advance   time.Duration
+		allow     bool   // expected Allow result; the request is then recorded
+		failed    bool   // outcome recorded when allowed
+		abandon   bool   // abandon instead of recording
+		wantState string // state after the step
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "opens after consecutive failures only",
+			steps: []step{
+				{name: "failure 1", allow: true, failed: true, wantState: CircuitClosed},
+				{name: "success resets", allow: true, wantState: CircuitClosed},
+				{name: "failure 1 again", allow: true, failed: true, wantState: CircuitClosed},
+				{name: "failure 2", allow: true, failed: true, wantState: CircuitClosed},
+				{name: "failure 3 opens", allow: true, failed: true, wantState: CircuitOpen},
+				{name: "fails fast", advance: 10 * time.Second, allow: false, wantState: CircuitOpen},
+			},
+		},
+		{
+			name: "successful trial closes",
+			steps: []step{
+				{allow: true, failed: true}, {allow: true, failed: true}, {allow: true, failed: true, wantState: CircuitOpen},
+				{name: "cooldown over", advance: 30 * time.Second, allow: true, wantState: CircuitClosed},
+				{name: "closed again", allow: true, wantState: CircuitClosed},
+			},
+		},
+		{
+			name: "failed trial reopens for another cooldown",
+			steps: []step{
+				{allow: true, failed: true}, {allow: true, failed: true}, {allow: true, failed: true, wantState: CircuitOpen},
+				{name: "trial fails", advance: 30 * time.Second, allow: true, failed: true, wantState: CircuitOpen},
+				{name: "still open", advance: 29 * time.Second, allow: false, wantState: CircuitOpen},
+				{name: "next trial", advance: time.Second, allow: true, wantState: CircuitClosed},
+			},
+		},
+		{
+			name: "abandoned trial frees the slot",
+			steps: []step{
+				{allow: true, failed: true}, {allow: true, failed: true}, {allow: true, failed: true, wantState: CircuitOpen},
+				{name: "trial abandoned", advance: 30 * time.Second, allow: true, abandon: true, wantState: CircuitHalfOpen},
+				{name: "another trial", allow: true, wantState: CircuitClosed},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, clock := newTestBreaker(3, 30*time.Second)
+			for i, s := range tt.steps {
+				clock.t = clock.t.Add(s.advance)
+				if got := b.Allow(); got != s.allow {
+					t.Fatalf("step %d %s: Allow() = %v, want %v", i, s.name, got, s.allow)
+				}
+				if s.allow {
+					if s.abandon {
+						b.Abandon()
+					} else {
+						b.Record(s.failed)
+					}
+				}
+				if s.wantState != "" {
+					if got := b.State(); got != s.wantState {
+						t.Fatalf("step %d %s: State() = %s, want %s", i, s.name, got, s.wantState)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBreakerAdmitsOneTrialAtATime(t *testing.T) {
+	b, clock := newTestBreaker(1, time.Minute)
+	b.Allow()
+	b.Record(true)
+	clock.t = clock.t.Add(time.Minute)
+	if b.State() != CircuitHalfOpen {
+		t.Fatalf("State() = %s, want %s", b.State(), CircuitHalfOpen)
+	}
+	if !b.Allow() {
+		t.Fatal("trial request refused")
+	}
+	if b.Allow() {
+		t.Fatal("second request admitted while the trial is in flight")
+	}
+}
+
+func TestBreakerDisabled(t *testing.T) {
+	for _, b := range []*Breaker{nil, NewBreaker(0, time.Minute)} {
+		for range 10 {
+			if !b.Allow() {
+				t.Fatal("disabled breaker refused a request")
+			}
+			b.Record(true)
+		}
+		if b.State() != CircuitClosed {
+			t.Fatalf("State() = %s, want %s", b.State(), CircuitClosed)
+		}
+	}
+}
@@ -1,17 +1,36 @@
 package coc
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ab-dauletkhan/coc/internal/cachehint"
+	"github.com/ab-dauletkhan/coc/internal/logging"
+	"github.com/ab-dauletkhan/coc/internal/metrics"
 )
 
+var tracer = otel.Tracer("github.com/ab-dauletkhan/coc/internal/coc")
+
 type Client struct {
 	baseURL string
 	token   string
 	http    *http.Client
+	limiter *Limiter
+	breaker *Breaker
+
+	healthMu sync.Mutex
+	health   Health
 }
 
 func NewClient(baseURL, token string) *Client {
@@ -24,6 +43,18 @@ func NewClient(baseURL, token string) *Client {
 	}
 }
 
+// WithBreaker makes requests fail fast with ErrCircuitOpen while b is open.
+func (c *Client) WithBreaker(b *Breaker) *Client {
+	c.breaker = b
+	return c
+}
+
+// WithLimiter makes every request wait for l before being sent.
+func (c *Client) WithLimiter(l *Limiter) *Client {
+	c.limiter = l
+	return c
+}
+
 func (c *Client) GetPlayerRaw(ctx context.Context, tag string) ([]byte, int, error) {
 	url := fmt.Sprintf("%s/players/%s", c.baseURL, tag)
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
@@ -32,36 +63,95 @@ func (c *Client) GetPlayerRaw(ctx context.Context, tag string) ([]byte, int, err
 	}
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
 	req.Header.Set("Accept", "application/json")
+	return c.do(req, "players", tag)
+}
 
-	resp, err := c.http.Do(req)
+func (c *Client) GetClanMembersRaw(ctx context.Context, tag string) ([]byte, int, error) {
+	url := fmt.Sprintf("%s/clans/%s/members", c.baseURL, tag)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, 0, err
 	}
-	defer resp.Body.Close()
-	b, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, resp.StatusCode, err
-	}
-	return b, resp.StatusCode, nil
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
+	req.Header.Set("Accept", "application/json")
+	return c.do(req, "clan_members", tag)
 }
 
-func (c *Client) GetClanMembersRaw(ctx context.Context, tag string) ([]byte, int, error) {
-	url := fmt.Sprintf("%s/clans/%s/members", c.baseURL, tag)
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+// VerifyPlayerToken checks a player's one-time API token from the in-game settings.
+// The response body reports "ok" or "invalid" in its status field.
+func (c *Client) VerifyPlayerToken(ctx context.Context, tag, token string) ([]byte, int, error) {
+	url := fmt.Sprintf("%s/players/%s/verifytoken", c.baseURL, tag)
+	payload, err := json.Marshal(struct {
+		Token string `json:"token"`
+	}{Token: token})
+	if err != nil {
+		return nil, 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
 	if err != nil {
 		return nil, 0, err
 	}
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
 	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	return c.do(req, "verify_token", tag)
+}
 
+// do sends req and records it under endpoint, a fixed name for the upstream route, in
+// a client span tagged with the player or clan tag. Requests are sent once, so the
+// attempt attribute is always 1.
+func (c *Client) do(req *http.Request, endpoint, tag string) ([]byte, int, error) {
+	ctx, span := tracer.Start(req.Context(), "coc."+endpoint, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("coc.tag", tag),
+		attribute.Int("coc.attempt", 1),
+		attribute.String("http.request.method", req.Method),
+	))
+	defer span.End()
+	req = req.WithContext(ctx)
+
+	if !c.breaker.Allow() {
+		cachehint.Uncacheable(ctx)
+		span.RecordError(ErrCircuitOpen)
+		span.SetStatus(codes.Error, ErrCircuitOpen.Error())
+		return nil, 0, ErrCircuitOpen
+	}
+	waitStart := time.Now()
+	if err := c.limiter.Wait(ctx); err != nil {
+		c.breaker.Abandon()
+		cachehint.Uncacheable(ctx)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "rate limiter wait aborted")
+		return nil, 0, err
+	}
+	span.SetAttributes(attribute.Int64("coc.limiter_wait_ms", time.Since(waitStart).Milliseconds()))
+	start := time.Now()
 	resp, err := c.http.Do(req)
 	if err != nil {
+		c.observe(ctx, 0, nil, err)
+		cachehint.Uncacheable(ctx)
+		metrics.ObserveUpstream(endpoint, 0, time.Since(start))
+		logging.FromContext(ctx).Warn("upstream request failed", "endpoint", endpoint, "tag", tag, "duration_ms", time.Since(start).Milliseconds(), "error", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return nil, 0, err
 	}
 	defer resp.Body.Close()
 	b, err := io.ReadAll(resp.Body)
+	c.observe(ctx, resp.StatusCode, b, err)
+	metrics.ObserveUpstream(endpoint, resp.StatusCode, time.Since(start))
+	logging.FromContext(ctx).Debug("upstream request", "endpoint", endpoint, "tag", tag, "status", resp.StatusCode, "duration_ms", time.Since(start).Milliseconds())
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= 500 {
+		span.SetStatus(codes.Error, resp.Status)
+	}
 	if err != nil {
+		cachehint.Uncacheable(ctx)
 		return nil, resp.StatusCode, err
 	}
+	if resp.StatusCode < 300 || resp.StatusCode == http.StatusNotFound {
+		cachehint.Observe(ctx, resp.Header, time.Now())
+	} else {
+		cachehint.Uncacheable(ctx)
+	}
 	return b, resp.StatusCode, nil
 }
@@ -0,0 +1,55 @@
+package coc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCallerDeadlinesDoNotCountAsUpstreamFailures(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	c := NewClient(srv.URL, "token").WithBreaker(NewBreaker(2, time.Minute))
+	for range 3 {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		_, _, err := c.GetPlayerRaw(ctx, "#P1")
+		cancel()
+		if err == nil {
+			t.Fatal("expected the request to time out")
+		}
+	}
+	h := c.Health()
+	if h.Circuit != CircuitClosed {
+		t.Errorf("circuit = %s, want %s", h.Circuit, CircuitClosed)
+	}
+	if !h.LastErrorAt.IsZero() {
+		t.Errorf("caller deadline recorded as an upstream error: %q", h.LastError)
+	}
+}
+
+func TestServerErrorsOpenTheCircuit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "token").WithBreaker(NewBreaker(2, time.Minute))
+	for range 2 {
+		if _, status, _ := c.GetPlayerRaw(context.Background(), "#P1"); status != http.StatusServiceUnavailable {
+			t.Fatalf("status = %d, want 503", status)
+		}
+	}
+	if _, _, err := c.GetPlayerRaw(context.Background(), "#P1"); err != ErrCircuitOpen {
+		t.Fatalf("err = %v, want ErrCircuitOpen", err)
+	}
+	if h := c.Health(); h.LastErrorStatus != http.StatusServiceUnavailable {
+		t.Errorf("LastErrorStatus = %d, want 503", h.LastErrorStatus)
+	}
+}
@@ -0,0 +1,94 @@
+package coc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// Health summarizes the outcome of recent requests to the API.
+type Health struct {
+	LastSuccessAt time.Time
+	LastErrorAt   time.Time
+	// LastError is the reason reported by the API (e.g. "accessDenied.invalidIp"), or
+	// the transport error when no response was received.
+	LastError string
+	// LastErrorStatus is the status of the last failed request; 0 without a response.
+	LastErrorStatus int
+	Circuit         string
+}
+
+// Health returns the outcome of recent requests and the circuit state.
+func (c *Client) Health() Health {
+	c.healthMu.Lock()
+	h := c.health
+	c.healthMu.Unlock()
+	h.Circuit = c.breaker.State()
+	return h
+}
+
+// observe feeds the outcome of one sent request to the breaker and Health. A request
+// that failed because its caller gave up, by cancelling or by its own deadline (a
+// member fetch timeout or a clan request deadline), says nothing about the API and
+// is left out of both.
+func (c *Client) observe(ctx context.Context, status int, body []byte, err error) {
+	if err != nil && ctx.Err() != nil {
+		c.breaker.Abandon()
+		return
+	}
+	c.breaker.Record(breakerFailure(status, err))
+	c.record(status, body, err)
+}
+
+// record updates Health with the outcome of one request. Responses the API gives for
+// valid requests (2xx, 3xx, 404) count as successes.
+func (c *Client) record(status int, body []byte, err error) {
+	now := time.Now()
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+	if err == nil && (status < 400 || status == http.StatusNotFound) {
+		c.health.LastSuccessAt = now
+		return
+	}
+	c.health.LastErrorAt = now
+	c.health.LastErrorStatus = status
+	c.health.LastError = failureReason(status, body, err)
+}
+
+// failureReason extracts the "reason" of an API error body, falling back to the
+// status text or the transport error.
+func failureReason(status int, body []byte, err error) string {
+	if status == 0 && err != nil {
+		return err.Error()
+	}
+	var apiErr struct {
+		Reason string `json:"reason"`
+	}
+	if json.Unmarshal(body, &apiErr) == nil && apiErr.Reason != "" {
+		return apiErr.Reason
+	}
+	if err != nil {
+		return err.Error()
+	}
+	return http.StatusText(status)
+}
+
+// breakerFailure reports whether an outcome counts towards opening the circuit:
+// transport errors and 5xx responses.
+func breakerFailure(status int, err error) bool {
+	return (err != nil && status == 0) || status >= 500
+}
+
+// Ping makes the cheapest authenticated request the API offers, so that Health
+// reflects the token and IP allowlist even when nothing else was requested lately.
+func (c *Client) Ping(ctx context.Context) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/locations?limit=1", nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Accept", "application/json")
+	_, status, err := c.do(req, "locations", "")
+	return status, err
+}
@@ -0,0 +1,41 @@
+package coc
+
+import (
+	"context"
+	"time"
+
+	"github.com/ab-dauletkhan/coc/internal/ratelimit"
+)
+
+// Limiter is a token bucket shared by every request made through a Client, so
+// interactive endpoints and background work draw from the same upstream budget.
+type Limiter struct {
+	bucket *ratelimit.Bucket
+}
+
+// NewLimiter allows rate requests per second with bursts of up to burst requests.
+// A non-positive rate disables limiting.
+func NewLimiter(rate float64, burst int) *Limiter {
+	return &Limiter{bucket: ratelimit.NewBucket(rate, burst, time.Now())}
+}
+
+// Wait blocks until a request may be made or ctx is done. A request whose ctx is
+// already done spends no token.
+func (l *Limiter) Wait(ctx context.Context) error {
+	for {
+		if err := ctx.Err(); err != nil || l == nil {
+			return err
+		}
+		d := l.bucket.Take(time.Now(), 1)
+		if d.Allowed {
+			return nil
+		}
+		t := time.NewTimer(d.RetryAfter)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
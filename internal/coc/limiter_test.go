@@ -0,0 +1,31 @@
+package coc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLimiterWait(t *testing.T) {
+	// One token, refilled only after 1000 seconds.
+	l := NewLimiter(0.001, 1)
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.Wait(cancelled); !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled wait = %v, want context.Canceled", err)
+	}
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("first wait = %v; a cancelled wait must not spend the token", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("wait on an empty bucket = %v, want context.DeadlineExceeded", err)
+	}
+
+	if err := NewLimiter(0, 1).Wait(context.Background()); err != nil {
+		t.Fatalf("disabled limiter: %v", err)
+	}
+}
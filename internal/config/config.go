@@ -1,24 +1,188 @@
 package config
 
 import (
-	"log"
+	"log/slog"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
 	ServerAddr  string
 	CocBaseURL  string
 	CocAPIToken string
+	// StorePath is the JSON file backing server-side state such as clan families.
+	StorePath string
+	// SnapshotsEnabled controls whether fetched player payloads are kept as history.
+	SnapshotsEnabled bool
+	// SnapshotRetention drops snapshots last seen longer ago than this (0 keeps all).
+	SnapshotRetention time.Duration
+	// SnapshotMaxPerPlayer caps stored snapshots per player (0 means unlimited).
+	SnapshotMaxPerPlayer int
+	// CocRateLimit is the upstream request budget per second shared by all callers
+	// (0 disables limiting); CocRateBurst is the allowed burst.
+	CocRateLimit float64
+	CocRateBurst int
+	// TrackerEnabled starts the background tracker polling registered players and clans.
+	TrackerEnabled         bool
+	TrackerTick            time.Duration
+	TrackerConcurrency     int
+	TrackerDefaultInterval time.Duration
+	TrackerMinInterval     time.Duration
+	// WebhookMaxAttempts bounds delivery attempts per event; retries wait WebhookBackoff,
+	// doubling up to WebhookMaxBackoff.
+	WebhookMaxAttempts int
+	WebhookBackoff     time.Duration
+	WebhookMaxBackoff  time.Duration
+	WebhookTimeout     time.Duration
+	WebhookConcurrency int
+	// WebhookAllowPrivate lets subscriptions target loopback, private, and link-local
+	// addresses, which are refused by default. Meant for local development.
+	WebhookAllowPrivate bool
+	// FanOutWorkers bounds player fetches in flight across all clan, family, batch, and
+	// tracker requests; MemberFetchTimeout bounds each fetch, and also linking an
+	// account, which verifies its token and fetches the player.
+	FanOutWorkers      int
+	MemberFetchTimeout time.Duration
+	// ClanRequestTimeout and FamilyRequestTimeout are the deadlines of clan and family
+	// requests; fetches that cannot finish before them are not started. Listing linked
+	// accounts uses ClanRequestTimeout. BatchRequestTimeout is the deadline of a
+	// /v1/players:batch request.
+	ClanRequestTimeout   time.Duration
+	FamilyRequestTimeout time.Duration
+	BatchRequestTimeout  time.Duration
+	// FamilyMaxClans caps the clans of a family, so a family request fans out to at
+	// most FamilyMaxClans member lists; raise FamilyRequestTimeout along with it.
+	FamilyMaxClans int
+	// TracesExporter selects where spans go: "otlp", "stdout", or "none". The OTLP
+	// endpoint is read by the exporter from OTEL_EXPORTER_OTLP_* variables.
+	TracesExporter string
+	ServiceName    string
+	// CocBreakerThreshold consecutive upstream failures open the circuit for
+	// CocBreakerCooldown (0, the default, disables the breaker).
+	CocBreakerThreshold int
+	CocBreakerCooldown  time.Duration
+	// ReadyProbeAfter is how long /readyz relies on recent upstream calls before
+	// probing the API itself.
+	ReadyProbeAfter time.Duration
+	// Server timeouts and limits; streamed responses lift the write timeout.
+	ServerReadTimeout       time.Duration
+	ServerReadHeaderTimeout time.Duration
+	ServerWriteTimeout      time.Duration
+	ServerIdleTimeout       time.Duration
+	ServerMaxHeaderBytes    int
+	// ShutdownTimeout bounds draining in-flight requests and background work on
+	// SIGINT/SIGTERM.
+	ShutdownTimeout time.Duration
+	// AuthEnabled requires an API key on /v1 requests. APIKeys are keys defined in
+	// the configuration; more can be created through the API by admin keys.
+	AuthEnabled bool
+	APIKeys     []APIKey
+	// APIRateLimit (requests per second), APIRateBurst, and APIDailyQuota apply to keys
+	// that do not set their own; 0 disables them.
+	APIRateLimit  float64
+	APIRateBurst  int
+	APIDailyQuota int
+	// InboundRateLimit is the request cost each client IP may spend per second (0
+	// disables limiting), InboundRateBurst its bucket size, and InboundRouteWeights the
+	// cost of routes by route pattern prefix; the longest prefix wins and other routes
+	// cost 1.
+	InboundRateLimit    float64
+	InboundRateBurst    int
+	InboundRouteWeights map[string]float64
+	// TrustedProxies lists the proxy IPs or CIDRs whose X-Forwarded-For and X-Real-IP
+	// headers are believed when determining the client IP.
+	TrustedProxies []string
+	// CompressionEnabled negotiates brotli or gzip for textual responses of at least
+	// CompressionMinSize bytes.
+	CompressionEnabled bool
+	CompressionMinSize int
+	// OpenAPIValidate checks requests and JSON responses against the OpenAPI spec,
+	// answering mismatching responses with 500. Meant for development.
+	OpenAPIValidate bool
 }
 
+// APIKey is a key parsed from API_KEYS.
+type APIKey struct {
+	Name   string
+	Secret string
+	Scopes []string
+	// UserID is the user the key acts as on /v1/me endpoints; it defaults to Name.
+	UserID string
+}
+
+// defaultRouteWeights roughly follow the upstream calls a request makes: a clan view
+// fetches every member, a family view every member of every clan.
+const defaultRouteWeights = "/v1/families/:name/hero-equipments=25,/v1/clans/:tag/hero-equipments=10,/v1/players:batch=10,/v1/me/accounts=5"
+
 func Load() Config {
 	cfg := Config{
-		ServerAddr:  getEnv("SERVER_ADDR", ":8080"),
-		CocBaseURL:  getEnv("COC_API_BASE", "https://api.clashofclans.com/v1"),
-		CocAPIToken: os.Getenv("COC_API_TOKEN"),
+		ServerAddr:           getEnv("SERVER_ADDR", ":8080"),
+		CocBaseURL:           getEnv("COC_API_BASE", "https://api.clashofclans.com/v1"),
+		CocAPIToken:          os.Getenv("COC_API_TOKEN"),
+		StorePath:            getEnv("STORE_PATH", "data/store.json"),
+		SnapshotsEnabled:     getEnvBool("SNAPSHOTS_ENABLED", true),
+		SnapshotRetention:    getEnvDuration("SNAPSHOT_RETENTION", 180*24*time.Hour),
+		SnapshotMaxPerPlayer: getEnvInt("SNAPSHOT_MAX_PER_PLAYER", 0),
+		CocRateLimit:         getEnvFloat("COC_RATE_LIMIT", 20),
+		CocRateBurst:         getEnvInt("COC_RATE_BURST", 20),
+
+		TrackerEnabled:         getEnvBool("TRACKER_ENABLED", true),
+		TrackerTick:            getEnvDuration("TRACKER_TICK", 5*time.Second),
+		TrackerConcurrency:     getEnvInt("TRACKER_CONCURRENCY", 2),
+		TrackerDefaultInterval: getEnvDuration("TRACKER_DEFAULT_INTERVAL", time.Hour),
+		TrackerMinInterval:     getEnvDuration("TRACKER_MIN_INTERVAL", time.Minute),
+
+		WebhookMaxAttempts:  getEnvInt("WEBHOOK_MAX_ATTEMPTS", 5),
+		WebhookBackoff:      getEnvDuration("WEBHOOK_BACKOFF", 5*time.Second),
+		WebhookMaxBackoff:   getEnvDuration("WEBHOOK_MAX_BACKOFF", 5*time.Minute),
+		WebhookTimeout:      getEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+		WebhookConcurrency:  getEnvInt("WEBHOOK_CONCURRENCY", 4),
+		WebhookAllowPrivate: getEnvBool("WEBHOOK_ALLOW_PRIVATE", false),
+
+		ServerReadTimeout:       getEnvDuration("SERVER_READ_TIMEOUT", 15*time.Second),
+		ServerReadHeaderTimeout: getEnvDuration("SERVER_READ_HEADER_TIMEOUT", 5*time.Second),
+		ServerWriteTimeout:      getEnvDuration("SERVER_WRITE_TIMEOUT", 90*time.Second),
+		ServerIdleTimeout:       getEnvDuration("SERVER_IDLE_TIMEOUT", 2*time.Minute),
+		ServerMaxHeaderBytes:    getEnvInt("SERVER_MAX_HEADER_BYTES", 1<<20),
+		ShutdownTimeout:         getEnvDuration("SHUTDOWN_TIMEOUT", 30*time.Second),
+
+		FanOutWorkers:        getEnvInt("FANOUT_WORKERS", 5),
+		MemberFetchTimeout:   getEnvDuration("MEMBER_FETCH_TIMEOUT", 6*time.Second),
+		ClanRequestTimeout:   getEnvDuration("CLAN_REQUEST_TIMEOUT", 10*time.Second),
+		FamilyRequestTimeout: getEnvDuration("FAMILY_REQUEST_TIMEOUT", 20*time.Second),
+		FamilyMaxClans:       getEnvInt("FAMILY_MAX_CLANS", 3),
+		BatchRequestTimeout:  getEnvDuration("BATCH_REQUEST_TIMEOUT", time.Minute),
+
+		TracesExporter: getEnv("OTEL_TRACES_EXPORTER", "none"),
+		ServiceName:    getEnv("OTEL_SERVICE_NAME", "coc-api"),
+
+		CocBreakerThreshold: getEnvInt("COC_BREAKER_THRESHOLD", 0),
+		CocBreakerCooldown:  getEnvDuration("COC_BREAKER_COOLDOWN", 30*time.Second),
+		ReadyProbeAfter:     getEnvDuration("READY_PROBE_AFTER", 30*time.Second),
+
+		AuthEnabled:   getEnvBool("API_AUTH_ENABLED", false),
+		APIKeys:       getEnvAPIKeys("API_KEYS"),
+		APIRateLimit:  getEnvFloat("API_RATE_LIMIT", 5),
+		APIRateBurst:  getEnvInt("API_RATE_BURST", 20),
+		APIDailyQuota: getEnvInt("API_DAILY_QUOTA", 10000),
+
+		InboundRateLimit:    getEnvFloat("INBOUND_RATE_LIMIT", 10),
+		InboundRateBurst:    getEnvInt("INBOUND_RATE_BURST", 100),
+		InboundRouteWeights: getEnvWeights("INBOUND_ROUTE_WEIGHTS", defaultRouteWeights),
+		TrustedProxies:      getEnvList("TRUSTED_PROXIES"),
+
+		CompressionEnabled: getEnvBool("COMPRESSION_ENABLED", true),
+		CompressionMinSize: getEnvInt("COMPRESSION_MIN_SIZE", 1024),
+
+		OpenAPIValidate: getEnvBool("OPENAPI_VALIDATE", false),
 	}
 	if cfg.CocAPIToken == "" {
-		log.Println("warning: COC_API_TOKEN is not set; upstream calls will fail")
+		slog.Warn("COC_API_TOKEN is not set; upstream calls will fail")
+	}
+	if cfg.AuthEnabled && len(cfg.APIKeys) == 0 {
+		slog.Warn("API_AUTH_ENABLED is set without API_KEYS; only keys already in the store will work")
 	}
 	return cfg
 }
@@ -29,3 +193,115 @@ func getEnv(key, def string) string {
 	}
 	return def
 }
+
+func getEnvBool(key string, def bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		slog.Warn("invalid configuration value; using default", "key", key, "value", v, "default", def)
+		return def
+	}
+	return b
+}
+
+func getEnvInt(key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		slog.Warn("invalid configuration value; using default", "key", key, "value", v, "default", def)
+		return def
+	}
+	return n
+}
+
+func getEnvFloat(key string, def float64) float64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		slog.Warn("invalid configuration value; using default", "key", key, "value", v, "default", def)
+		return def
+	}
+	return f
+}
+
+func getEnvDuration(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		slog.Warn("invalid configuration value; using default", "key", key, "value", v, "default", def.String())
+		return def
+	}
+	return d
+}
+
+// getEnvAPIKeys parses comma-separated keys of the form name:secret:scopes[:user],
+// where scopes are separated by "|". Malformed entries are skipped.
+func getEnvAPIKeys(key string) []APIKey {
+	var out []APIKey
+	for _, entry := range strings.Split(os.Getenv(key), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, ":")
+		if len(parts) < 3 || len(parts) > 4 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+			slog.Warn("invalid API key entry; skipping", "key", key, "name", parts[0])
+			continue
+		}
+		k := APIKey{Name: parts[0], Secret: parts[1], Scopes: strings.Split(parts[2], "|"), UserID: parts[0]}
+		if len(parts) == 4 && parts[3] != "" {
+			k.UserID = parts[3]
+		}
+		out = append(out, k)
+	}
+	return out
+}
+
+// getEnvList parses a comma-separated list, dropping empty items.
+func getEnvList(key string) []string {
+	var out []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// getEnvWeights parses comma-separated prefix=weight pairs, falling back to def when
+// the variable is unset. Malformed pairs are skipped.
+func getEnvWeights(key, def string) map[string]float64 {
+	v := os.Getenv(key)
+	if v == "" {
+		v = def
+	}
+	out := map[string]float64{}
+	for _, pair := range strings.Split(v, ",") {
+		prefix, weight, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || prefix == "" {
+			if pair != "" {
+				slog.Warn("invalid route weight; skipping", "key", key, "value", pair)
+			}
+			continue
+		}
+		w, err := strconv.ParseFloat(weight, 64)
+		if err != nil || w < 0 {
+			slog.Warn("invalid route weight; skipping", "key", key, "value", pair)
+			continue
+		}
+		out[prefix] = w
+	}
+	return out
+}
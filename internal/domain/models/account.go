@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// AccountLink records that a user proved ownership of a player account.
+type AccountLink struct {
+	UserID    string
+	PlayerTag string // normalized as #TAG
+	LinkedAt  time.Time
+}
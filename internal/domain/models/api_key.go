@@ -0,0 +1,52 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// API key scopes. ScopeRead covers GET requests, ScopeWrite every other method, and
+// ScopeAdmin key management; an admin key may do everything.
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+	ScopeAdmin = "admin"
+)
+
+// Scopes lists every scope an API key may be granted.
+var Scopes = []string{ScopeRead, ScopeWrite, ScopeAdmin}
+
+// APIKey authenticates a client of this API. Only a hash of the secret is kept.
+type APIKey struct {
+	ID   string
+	Name string
+	// SecretHash is the hex SHA-256 of the secret.
+	SecretHash string
+	Scopes     []string
+	// UserID is the user the key acts as on /v1/me endpoints.
+	UserID string
+	// RateLimit (requests per second), RateBurst, and DailyQuota override the
+	// defaults when positive.
+	RateLimit  float64
+	RateBurst  int
+	DailyQuota int
+	// Static keys come from the configuration and cannot be changed through the API.
+	Static    bool
+	CreatedAt time.Time
+}
+
+// Allows reports whether the key was granted scope.
+func (k APIKey) Allows(scope string) bool {
+	return slices.Contains(k.Scopes, scope) || slices.Contains(k.Scopes, ScopeAdmin)
+}
+
+// APIKeyUsage counts the requests of a key on one UTC day.
+type APIKeyUsage struct {
+	KeyID string
+	Day   string // YYYY-MM-DD
+	// Requests were admitted; RateLimited and QuotaExceeded were rejected with 429.
+	Requests      int
+	RateLimited   int
+	QuotaExceeded int
+	LastUsedAt    time.Time
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// ClanRosterMember is the part of a clan member entry we watch for changes.
+type ClanRosterMember struct {
+	Tag  string
+	Name string
+	Role string
+}
+
+// ClanEventType names a detected clan membership change.
+type ClanEventType string
+
+const (
+	ClanMemberJoined   ClanEventType = "member_joined"
+	ClanMemberLeft     ClanEventType = "member_left"
+	ClanMemberPromoted ClanEventType = "member_promoted"
+	ClanMemberDemoted  ClanEventType = "member_demoted"
+	ClanMemberRenamed  ClanEventType = "member_renamed"
+)
+
+// ClanEvent is a membership change detected between two consecutive member lists.
+// OldValue and NewValue hold the previous and current role or name for role and
+// name changes.
+type ClanEvent struct {
+	ID         string
+	ClanTag    string
+	Type       ClanEventType
+	OccurredAt time.Time
+	PlayerTag  string
+	PlayerName string
+	OldValue   string
+	NewValue   string
+}
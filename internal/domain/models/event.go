@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// EventType names a change announced to subscribers such as webhooks.
+type EventType string
+
+const (
+	EventEquipmentUpgraded  EventType = "equipment.upgraded"
+	EventEquipmentUnlocked  EventType = "equipment.unlocked"
+	EventClanMemberJoined   EventType = "clan.member_joined"
+	EventClanMemberLeft     EventType = "clan.member_left"
+	EventClanMemberPromoted EventType = "clan.member_promoted"
+	EventClanMemberDemoted  EventType = "clan.member_demoted"
+	EventClanMemberRenamed  EventType = "clan.member_renamed"
+)
+
+// EventTypes lists every event type that can be published.
+var EventTypes = []EventType{
+	EventEquipmentUpgraded,
+	EventEquipmentUnlocked,
+	EventClanMemberJoined,
+	EventClanMemberLeft,
+	EventClanMemberPromoted,
+	EventClanMemberDemoted,
+	EventClanMemberRenamed,
+}
+
+// ClanEventTypeFor returns the published event type of a clan membership change.
+func ClanEventTypeFor(t ClanEventType) EventType {
+	return EventType("clan." + string(t))
+}
+
+// Event is a detected change. Data holds type-specific fields and is sent as is.
+type Event struct {
+	ID         string
+	Type       EventType
+	OccurredAt time.Time
+	Data       map[string]any
+}
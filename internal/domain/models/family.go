@@ -0,0 +1,8 @@
+package models
+
+// ClanFamily is a named group of clans (e.g. a main clan and its feeders) that is
+// reported on as a whole.
+type ClanFamily struct {
+	Name     string
+	ClanTags []string // normalized as #TAG
+}
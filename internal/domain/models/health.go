@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// UpstreamHealth summarizes recent calls to the official API.
+type UpstreamHealth struct {
+	LastSuccessAt time.Time
+	LastErrorAt   time.Time
+	// LastError is the reason of the last failed call, e.g. "accessDenied.invalidIp".
+	LastError string
+	// LastErrorStatus is 0 when the last failed call got no response.
+	LastErrorStatus int
+	// Circuit is "closed", "open", or "half-open".
+	Circuit string
+}
+
+// CatalogInfo describes the loaded equipment catalog.
+type CatalogInfo struct {
+	Items int
+	// Version is the catalog's declared version, empty when it declares none.
+	Version string
+	// Checksum identifies the loaded file contents.
+	Checksum string
+	LoadedAt time.Time
+}
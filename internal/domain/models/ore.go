@@ -1,8 +1,20 @@
 package models
 
-// OreTotals represents aggregated ore amounts.
+// OreTotals represents aggregated ore amounts. It is returned as is in API responses.
 type OreTotals struct {
-	Shiny  int
-	Glowy  int
-	Starry int
+	Shiny  int `json:"shiny"`
+	Glowy  int `json:"glowy"`
+	Starry int `json:"starry"`
+}
+
+// Add accumulates o into t.
+func (t *OreTotals) Add(o OreTotals) {
+	t.Shiny += o.Shiny
+	t.Glowy += o.Glowy
+	t.Starry += o.Starry
+}
+
+// Sub returns t minus o.
+func (t OreTotals) Sub(o OreTotals) OreTotals {
+	return OreTotals{Shiny: t.Shiny - o.Shiny, Glowy: t.Glowy - o.Glowy, Starry: t.Starry - o.Starry}
 }
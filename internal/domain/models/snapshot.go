@@ -0,0 +1,50 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// HeroLevel captures a player's hero level details.
+type HeroLevel struct {
+	Name     string
+	Level    int
+	MaxLevel int
+}
+
+// PlayerSnapshot is a normalized, timestamped view of a player's progress.
+// Consecutive fetches that change nothing are folded into one snapshot whose
+// LastSeenAt is advanced instead of storing a duplicate.
+type PlayerSnapshot struct {
+	PlayerTag  string // normalized as #TAG
+	TakenAt    time.Time
+	LastSeenAt time.Time
+	TownHall   int
+	Heroes     []HeroLevel
+	Equipment  []EquipmentLevel
+}
+
+// Normalize sorts heroes and equipment by name so snapshots compare reliably.
+func (s *PlayerSnapshot) Normalize() {
+	sort.Slice(s.Heroes, func(i, j int) bool { return s.Heroes[i].Name < s.Heroes[j].Name })
+	sort.Slice(s.Equipment, func(i, j int) bool { return s.Equipment[i].Name < s.Equipment[j].Name })
+}
+
+// SameLevels reports whether both snapshots record identical progress. Both must be
+// normalized.
+func (s PlayerSnapshot) SameLevels(o PlayerSnapshot) bool {
+	if s.TownHall != o.TownHall || len(s.Heroes) != len(o.Heroes) || len(s.Equipment) != len(o.Equipment) {
+		return false
+	}
+	for i := range s.Heroes {
+		if s.Heroes[i] != o.Heroes[i] {
+			return false
+		}
+	}
+	for i := range s.Equipment {
+		if s.Equipment[i] != o.Equipment[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// TrackedKind distinguishes what a tracked target refers to.
+type TrackedKind string
+
+const (
+	TrackedPlayer TrackedKind = "player"
+	TrackedClan   TrackedKind = "clan"
+)
+
+// TrackedTarget is a player or clan polled periodically by the tracker.
+type TrackedTarget struct {
+	Kind      TrackedKind
+	Tag       string // normalized as #TAG
+	Interval  time.Duration
+	CreatedAt time.Time
+	Status    TrackerRun
+}
+
+// TrackerRun records the outcome of the latest polls of a target.
+type TrackerRun struct {
+	LastRunAt     time.Time
+	LastSuccessAt time.Time
+	LastError     string
+	// LastFailedMembers counts members of a tracked clan that could not be fetched.
+	LastFailedMembers int
+	Runs              int
+	Failures          int
+}
+
+// NextRunAt returns when the target is due again.
+func (t TrackedTarget) NextRunAt() time.Time {
+	if t.Status.LastRunAt.IsZero() {
+		return t.CreatedAt
+	}
+	return t.Status.LastRunAt.Add(t.Interval)
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// WebhookSubscription delivers events of the selected types to a URL. The event
+// type "*" selects every type.
+type WebhookSubscription struct {
+	ID         string
+	URL        string
+	Secret     string
+	EventTypes []EventType
+	CreatedAt  time.Time
+}
+
+// Wants reports whether the subscription selects events of type t.
+func (s WebhookSubscription) Wants(t EventType) bool {
+	for _, et := range s.EventTypes {
+		if et == t || et == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery records one attempt to deliver an event to a subscription.
+type WebhookDelivery struct {
+	ID             string
+	SubscriptionID string
+	EventID        string
+	EventType      EventType
+	Attempt        int
+	AttemptedAt    time.Time
+	Duration       time.Duration
+	// StatusCode is 0 when no response was received.
+	StatusCode int
+	Error      string
+	Success    bool
+}
@@ -1,5 +1,7 @@
 package ports
 
+import "github.com/ab-dauletkhan/coc/internal/domain/models"
+
 // CatalogRepository is a secondary port for accessing the equipment catalog.
 type CatalogRepository interface {
 	// GetRarity returns the rarity for the given equipment name or empty when unknown.
@@ -12,6 +14,8 @@ type CatalogRepository interface {
 	CostsEpic() []OreCost
 	// ListEquipmentNames returns known equipment names in the catalog.
 	ListEquipmentNames() []string
+	// Info describes the loaded catalog; Items is 0 when none could be loaded.
+	Info() models.CatalogInfo
 }
 
 // OreCost is a small value object used by the catalog port.
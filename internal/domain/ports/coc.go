@@ -1,6 +1,10 @@
 package ports
 
-import "context"
+import (
+	"context"
+
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
+)
 
 // PlayerAPI defines secondary port for fetching player data from an external service.
 type PlayerAPI interface {
@@ -11,3 +15,16 @@ type PlayerAPI interface {
 type ClanAPI interface {
 	GetClanMembersRaw(ctx context.Context, tag string) ([]byte, int, error)
 }
+
+// PlayerVerificationAPI defines secondary port for proving ownership of a player account.
+type PlayerVerificationAPI interface {
+	VerifyPlayerTokenRaw(ctx context.Context, tag, token string) ([]byte, int, error)
+}
+
+// UpstreamMonitor defines secondary port for the health of the external service.
+type UpstreamMonitor interface {
+	UpstreamHealth() models.UpstreamHealth
+	// Probe makes a cheap authenticated call so UpstreamHealth reflects the current
+	// token and IP allowlist.
+	Probe(ctx context.Context) error
+}
@@ -0,0 +1,19 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
+)
+
+// EventPublisher is a port for announcing detected changes. Implementations must not
+// block the caller on slow subscribers.
+type EventPublisher interface {
+	Publish(ctx context.Context, event models.Event)
+}
+
+// WebhookSender is a secondary port for sending a signed webhook request.
+type WebhookSender interface {
+	// Send posts body to url with the given headers and returns the response status.
+	Send(ctx context.Context, url string, headers map[string]string, body []byte) (int, error)
+}
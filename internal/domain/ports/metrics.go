@@ -0,0 +1,10 @@
+package ports
+
+import "time"
+
+// Metrics is a port for measurements taken inside the use cases; adapters record
+// their own measurements directly.
+type Metrics interface {
+	// ObserveFanOut records fetching the players of members in one fan-out.
+	ObserveFanOut(members int, d time.Duration)
+}
@@ -0,0 +1,117 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/ab-dauletkhan/coc/internal/domain/models"
+)
+
+// FamilyRepository is a secondary port for persisting clan families.
+type FamilyRepository interface {
+	// ListFamilies returns all families ordered by name.
+	ListFamilies(ctx context.Context) ([]models.ClanFamily, error)
+	// GetFamily returns the named family; ok is false when it does not exist.
+	GetFamily(ctx context.Context, name string) (family models.ClanFamily, ok bool, err error)
+	// SaveFamily creates or replaces a family.
+	SaveFamily(ctx context.Context, family models.ClanFamily) error
+	// DeleteFamily removes a family; ok is false when it did not exist.
+	DeleteFamily(ctx context.Context, name string) (ok bool, err error)
+}
+
+// AccountLinkRepository is a secondary port for persisting verified links between
+// users of this API and the player accounts they own. A player tag is linked to at
+// most one user.
+type AccountLinkRepository interface {
+	// ListAccountLinks returns the user's links ordered by link time.
+	ListAccountLinks(ctx context.Context, userID string) ([]models.AccountLink, error)
+	// SaveAccountLink links the player tag to the user, replacing any previous owner.
+	SaveAccountLink(ctx context.Context, link models.AccountLink) error
+	// DeleteAccountLink removes the user's link to the tag; ok is false when absent.
+	DeleteAccountLink(ctx context.Context, userID, playerTag string) (ok bool, err error)
+}
+
+// SnapshotRepository is a secondary port for persisting player snapshots.
+type SnapshotRepository interface {
+	// SaveSnapshot stores a normalized snapshot and returns the player's latest snapshot
+	// before the call (hadPrevious is false for the first one), read in the same critical
+	// section so concurrent saves of one player each see a distinct predecessor. When it
+	// records the same levels as that latest snapshot, the latest one's LastSeenAt is
+	// advanced instead and stored is false.
+	SaveSnapshot(ctx context.Context, snapshot models.PlayerSnapshot) (previous models.PlayerSnapshot, hadPrevious, stored bool, err error)
+	// ListSnapshots returns the player's snapshots taken within [from, to] ordered by
+	// TakenAt, preceded by the latest snapshot taken before from so callers have a
+	// baseline. Zero bounds are open.
+	ListSnapshots(ctx context.Context, playerTag string, from, to time.Time) ([]models.PlayerSnapshot, error)
+	// LatestSnapshot returns the player's most recent snapshot; ok is false when none is stored.
+	LatestSnapshot(ctx context.Context, playerTag string) (snapshot models.PlayerSnapshot, ok bool, err error)
+	// PruneSnapshots deletes snapshots last seen before cutoff (when non-zero) and
+	// keeps at most maxPerPlayer snapshots per player (when positive). The latest
+	// snapshot of a player is always kept. An empty playerTag prunes every player.
+	PruneSnapshots(ctx context.Context, playerTag string, cutoff time.Time, maxPerPlayer int) (removed int, err error)
+}
+
+// TrackerRepository is a secondary port for persisting tracked players and clans.
+type TrackerRepository interface {
+	// ListTrackedTargets returns all targets ordered by kind and tag.
+	ListTrackedTargets(ctx context.Context) ([]models.TrackedTarget, error)
+	// SaveTrackedTarget registers a target or replaces its settings, keeping its run status.
+	SaveTrackedTarget(ctx context.Context, target models.TrackedTarget) error
+	// DeleteTrackedTarget unregisters a target; ok is false when it was not tracked.
+	DeleteTrackedTarget(ctx context.Context, kind models.TrackedKind, tag string) (ok bool, err error)
+	// RecordTrackerRun stores the run status of a target; unknown targets are ignored.
+	RecordTrackerRun(ctx context.Context, kind models.TrackedKind, tag string, run models.TrackerRun) error
+}
+
+// ClanEventRepository is a secondary port for persisting the latest observed member
+// list of tracked clans and the membership changes detected between lists.
+type ClanEventRepository interface {
+	// GetClanRoster returns the last saved member list; ok is false when none exists.
+	GetClanRoster(ctx context.Context, clanTag string) (roster []models.ClanRosterMember, ok bool, err error)
+	// SaveClanRoster replaces the saved member list and stores the events detected
+	// against the previous one in the same write, so neither survives a crash without
+	// the other.
+	SaveClanRoster(ctx context.Context, clanTag string, roster []models.ClanRosterMember, events []models.ClanEvent) error
+	// ListClanEvents returns a clan's events within [from, to] ordered by time, keeping
+	// only the given types when any are passed. Zero bounds are open.
+	ListClanEvents(ctx context.Context, clanTag string, from, to time.Time, types []models.ClanEventType) ([]models.ClanEvent, error)
+}
+
+// WebhookRepository is a secondary port for persisting webhook subscriptions and
+// their delivery log.
+type WebhookRepository interface {
+	ListWebhooks(ctx context.Context) ([]models.WebhookSubscription, error)
+	// GetWebhook returns a subscription by ID; ok is false when it does not exist.
+	GetWebhook(ctx context.Context, id string) (sub models.WebhookSubscription, ok bool, err error)
+	SaveWebhook(ctx context.Context, sub models.WebhookSubscription) error
+	// DeleteWebhook removes a subscription and its deliveries; ok is false when absent.
+	DeleteWebhook(ctx context.Context, id string) (ok bool, err error)
+	AppendWebhookDelivery(ctx context.Context, delivery models.WebhookDelivery) error
+	// ListWebhookDeliveries returns up to limit of a subscription's most recent
+	// deliveries, newest first.
+	ListWebhookDeliveries(ctx context.Context, subscriptionID string, limit int) ([]models.WebhookDelivery, error)
+}
+
+// StoreHealth is a secondary port reporting whether the store can persist changes.
+type StoreHealth interface {
+	Ping(ctx context.Context) error
+}
+
+// APIKeyRepository is a secondary port for persisting API keys managed through the
+// API and their daily usage.
+type APIKeyRepository interface {
+	// ListAPIKeys returns all keys ordered by creation time.
+	ListAPIKeys(ctx context.Context) ([]models.APIKey, error)
+	// FindAPIKey returns the key whose secret hashes to secretHash; ok is false when
+	// there is none.
+	FindAPIKey(ctx context.Context, secretHash string) (key models.APIKey, ok bool, err error)
+	SaveAPIKey(ctx context.Context, key models.APIKey) error
+	// DeleteAPIKey removes a key and its usage; ok is false when it did not exist.
+	DeleteAPIKey(ctx context.Context, id string) (ok bool, err error)
+	// GetAPIKeyUsage returns a key's usage on day; a day without requests is zero.
+	GetAPIKeyUsage(ctx context.Context, keyID, day string) (models.APIKeyUsage, error)
+	// SaveAPIKeyUsage stores a key's usage for usage.Day.
+	SaveAPIKeyUsage(ctx context.Context, usage models.APIKeyUsage) error
+	// ListAPIKeyUsage returns a key's recorded days, oldest first.
+	ListAPIKeyUsage(ctx context.Context, keyID string) ([]models.APIKeyUsage, error)
+}
@@ -0,0 +1,60 @@
+// Package logging builds the service's slog logger and carries per-request loggers
+// through contexts.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// New returns a logger writing to w at level ("debug", "info", "warn", "error") in
+// format ("json" or "text"). Unknown values fall back to info and JSON and are
+// reported in the returned error, which callers should log but not treat as fatal.
+func New(w io.Writer, level, format string) (*slog.Logger, error) {
+	var errs []string
+	lvl := slog.LevelInfo
+	if err := lvl.UnmarshalText([]byte(level)); level != "" && err != nil {
+		errs = append(errs, fmt.Sprintf("invalid LOG_LEVEL=%q; using info", level))
+		lvl = slog.LevelInfo
+	}
+	opts := &slog.HandlerOptions{Level: lvl}
+	var h slog.Handler
+	switch strings.ToLower(format) {
+	case "text":
+		h = slog.NewTextHandler(w, opts)
+	case "json", "":
+		h = slog.NewJSONHandler(w, opts)
+	default:
+		errs = append(errs, fmt.Sprintf("invalid LOG_FORMAT=%q; using json", format))
+		h = slog.NewJSONHandler(w, opts)
+	}
+	if len(errs) > 0 {
+		return slog.New(h), fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return slog.New(h), nil
+}
+
+type loggerKey struct{}
+
+// WithLogger returns a context carrying logger.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the logger carried by ctx, or the default logger. Records are
+// tagged with the trace and span IDs of the span in ctx, if any.
+func FromContext(ctx context.Context) *slog.Logger {
+	logger, ok := ctx.Value(loggerKey{}).(*slog.Logger)
+	if !ok {
+		logger = slog.Default()
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		logger = logger.With("trace_id", sc.TraceID().String(), "span_id", sc.SpanID().String())
+	}
+	return logger
+}
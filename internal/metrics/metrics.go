@@ -0,0 +1,135 @@
+// Package metrics holds the Prometheus collectors of the service and the handler
+// exposing them.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests served, by method, route, and status.",
+	}, []string{"method", "route", "status"})
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency, by method, route, and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	upstreamRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "coc_upstream_requests_total",
+		Help: "Requests to the Clash of Clans API, by endpoint and status (\"error\" when no response was received).",
+	}, []string{"endpoint", "status"})
+	upstreamDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "coc_upstream_request_duration_seconds",
+		Help:    "Clash of Clans API latency, by endpoint and status, excluding time spent waiting for the rate limiter.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"endpoint", "status"})
+
+	fanOutDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "coc_fanout_duration_seconds",
+		Help:    "Time to fetch the players of one clan, family, batch, or linked account set.",
+		Buckets: []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 30, 60},
+	})
+	fanOutMembers = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "coc_fanout_members",
+		Help:    "Players fetched per fan-out.",
+		Buckets: []float64{1, 5, 10, 20, 30, 50, 100, 200, 500},
+	})
+
+	catalogLoads = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "catalog_loads_total",
+		Help: "Equipment catalog loads, by kind (load at startup, reload on SIGHUP) and result.",
+	}, []string{"kind", "result"})
+	unknownEquipment = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "catalog_unknown_equipment_total",
+		Help: "Catalog lookups of equipment names missing from the catalog, by name.",
+	}, []string{"name"})
+
+	rateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_rate_limited_total",
+		Help: "Requests rejected by the per-IP inbound rate limiter, by route.",
+	}, []string{"route"})
+
+	apiKeyRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "api_key_requests_total",
+		Help: "Authenticated /v1 requests, by API key name and result (ok, forbidden, rate_limited, quota_exceeded, or unauthorized without a valid key).",
+	}, []string{"key", "result"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests, httpDuration,
+		upstreamRequests, upstreamDuration,
+		fanOutDuration, fanOutMembers,
+		catalogLoads, unknownEquipment,
+		rateLimited, apiKeyRequests,
+	)
+}
+
+// Handler serves the collected metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// ObserveHTTPRequest records one served request. route is the route pattern, not the
+// request path, to keep the label set bounded.
+func ObserveHTTPRequest(method, route string, status int, d time.Duration) {
+	s := strconv.Itoa(status)
+	httpRequests.WithLabelValues(method, route, s).Inc()
+	httpDuration.WithLabelValues(method, route, s).Observe(d.Seconds())
+}
+
+// ObserveUpstream records one Clash of Clans API call; status 0 means no response.
+func ObserveUpstream(endpoint string, status int, d time.Duration) {
+	s := "error"
+	if status > 0 {
+		s = strconv.Itoa(status)
+	}
+	upstreamRequests.WithLabelValues(endpoint, s).Inc()
+	upstreamDuration.WithLabelValues(endpoint, s).Observe(d.Seconds())
+}
+
+// ObserveCatalogLoad records a catalog load of the given kind ("load" or "reload").
+func ObserveCatalogLoad(kind string, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	catalogLoads.WithLabelValues(kind, result).Inc()
+}
+
+// UnknownEquipment records a catalog lookup that found no equipment with name.
+func UnknownEquipment(name string) {
+	unknownEquipment.WithLabelValues(name).Inc()
+}
+
+// RateLimited records a request rejected by the inbound rate limiter.
+func RateLimited(route string) {
+	rateLimited.WithLabelValues(route).Inc()
+}
+
+// ObserveAPIKeyRequest records the outcome of authenticating and admitting a request.
+// key is the key name, or empty when no valid key was presented.
+func ObserveAPIKeyRequest(key, result string) {
+	apiKeyRequests.WithLabelValues(key, result).Inc()
+}
+
+// Recorder implements ports.Metrics for measurements taken in the use cases.
+type Recorder struct{}
+
+func (Recorder) ObserveFanOut(members int, d time.Duration) {
+	fanOutMembers.Observe(float64(members))
+	fanOutDuration.Observe(d.Seconds())
+}
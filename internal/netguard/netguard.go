@@ -0,0 +1,62 @@
+// Package netguard keeps outgoing requests to user-supplied URLs, such as webhook
+// deliveries, away from the service's own network: loopback, private, link-local,
+// and unspecified addresses are refused.
+package netguard
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+	"syscall"
+)
+
+// ErrForbiddenAddress is returned for destinations inside a non-public network.
+var ErrForbiddenAddress = errors.New("destination address is not public")
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598), which is not routable
+// on the internet either.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// Public reports whether addr may be dialed.
+func Public(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() &&
+		!addr.IsLoopback() &&
+		!addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast() &&
+		!addr.IsUnspecified() &&
+		!sharedAddressSpace.Contains(addr) &&
+		!(addr.Is4() && addr.As4()[0] == 0)
+}
+
+// CheckHost rejects a URL host that is obviously not public without resolving it: an
+// IP literal outside the public ranges or a localhost name. Names are checked again
+// when dialed (see Control), since they can resolve to anything.
+func CheckHost(host string) error {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+	}
+	if addr, err := netip.ParseAddr(strings.Trim(host, "[]")); err == nil && !Public(addr) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+	}
+	return nil
+}
+
+// Control is a net.Dialer Control function refusing connections to non-public
+// addresses. It runs after name resolution, for every address tried, so a name
+// that resolves (or is rebound) to an internal address is refused too.
+func Control(network, address string, _ syscall.RawConn) error {
+	ap, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, address)
+	}
+	if !Public(ap.Addr()) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, ap.Addr())
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package netguard
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestPublic(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"100.64.0.1", false},
+		{"224.0.0.1", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:10.0.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := Public(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("Public(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestCheckHost(t *testing.T) {
+	tests := []struct {
+		host    string
+		allowed bool
+	}{
+		{"example.com", true},
+		{"93.184.216.34", true},
+		{"localhost", false},
+		{"LOCALHOST.", false},
+		{"api.localhost", false},
+		{"127.0.0.1", false},
+		{"[::1]", false},
+		{"::1", false},
+		{"169.254.169.254", false},
+	}
+	for _, tt := range tests {
+		err := CheckHost(tt.host)
+		if (err == nil) != tt.allowed {
+			t.Errorf("CheckHost(%q) = %v, want allowed %v", tt.host, err, tt.allowed)
+		}
+	}
+}
+
+func TestControlRefusesLoopbackConnections(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	dialer := &net.Dialer{Control: Control}
+	_, err := dialer.DialContext(context.Background(), "tcp", srv.Listener.Addr().String())
+	if !errors.Is(err, ErrForbiddenAddress) {
+		t.Fatalf("dial %s: got %v, want ErrForbiddenAddress", srv.Listener.Addr(), err)
+	}
+}
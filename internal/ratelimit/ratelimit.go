@@ -0,0 +1,128 @@
+// Package ratelimit provides token buckets for limiting inbound requests, alone or
+// keyed by client, and the upstream requests of the Clash of Clans client.
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Bucket refills rate tokens per second up to burst. A non-positive rate admits
+// everything.
+type Bucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func NewBucket(rate float64, burst int, now time.Time) *Bucket {
+	b := float64(max(burst, 1))
+	return &Bucket{rate: rate, burst: b, tokens: b, last: now}
+}
+
+// Decision is the outcome of taking tokens from a bucket.
+type Decision struct {
+	Allowed bool
+	// Limit is the bucket size and Remaining the whole tokens left after the request;
+	// both are 0 when limiting is disabled. Window is the time to refill an empty bucket.
+	Limit     int
+	Remaining int
+	Window    time.Duration
+	// Reset is how long until the bucket is full again; RetryAfter, set when the
+	// request was rejected, how long until it would be admitted.
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// Take spends cost tokens when available. A cost above the bucket size is capped so
+// that expensive requests remain possible on a full bucket.
+func (b *Bucket) Take(now time.Time, cost float64) Decision {
+	if b.rate <= 0 {
+		return Decision{Allowed: true}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(now)
+	cost = math.Min(cost, b.burst)
+	d := Decision{Limit: int(b.burst), Window: b.wait(b.burst)}
+	if b.tokens >= cost {
+		b.tokens -= cost
+		d.Allowed = true
+	} else {
+		d.RetryAfter = b.wait(cost - b.tokens)
+	}
+	d.Remaining = int(b.tokens)
+	d.Reset = b.wait(b.burst - b.tokens)
+	return d
+}
+
+// Matches reports whether the bucket was created with rate and burst.
+func (b *Bucket) Matches(rate float64, burst int) bool {
+	return b.rate == rate && b.burst == float64(max(burst, 1))
+}
+
+// full reports whether the bucket has refilled completely by now.
+func (b *Bucket) full(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(now)
+	return b.tokens >= b.burst
+}
+
+func (b *Bucket) refill(now time.Time) {
+	if now.After(b.last) {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+	}
+}
+
+// wait is rounded up so a client waiting that long finds the tokens refilled.
+func (b *Bucket) wait(tokens float64) time.Duration {
+	return time.Duration(math.Ceil(tokens / b.rate * float64(time.Second)))
+}
+
+// sweepInterval is how often a Keyed limiter drops buckets of idle clients.
+const sweepInterval = time.Minute
+
+// Keyed keeps one bucket per key, such as a client IP. Buckets that have refilled
+// completely are dropped periodically, so idle clients cost no memory.
+type Keyed struct {
+	rate  float64
+	burst int
+	now   func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*Bucket
+	lastSweep time.Time
+}
+
+func NewKeyed(rate float64, burst int) *Keyed {
+	return &Keyed{rate: rate, burst: burst, now: time.Now, buckets: map[string]*Bucket{}, lastSweep: time.Now()}
+}
+
+// Take spends cost tokens from key's bucket.
+func (k *Keyed) Take(key string, cost float64) Decision {
+	if k.rate <= 0 {
+		return Decision{Allowed: true}
+	}
+	now := k.now()
+	k.mu.Lock()
+	if now.Sub(k.lastSweep) >= sweepInterval {
+		for id, b := range k.buckets {
+			if b.full(now) {
+				delete(k.buckets, id)
+			}
+		}
+		k.lastSweep = now
+	}
+	b, ok := k.buckets[key]
+	if !ok {
+		b = NewBucket(k.rate, k.burst, now)
+		k.buckets[key] = b
+	}
+	k.mu.Unlock()
+	return b.Take(now, cost)
+}
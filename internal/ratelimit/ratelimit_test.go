@@ -0,0 +1,89 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBucketWeightedCosts(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := NewBucket(2, 10, start)
+
+	steps := []struct {
+		name       string
+		after      time.Duration
+		cost       float64
+		allowed    bool
+		remaining  int
+		retryAfter time.Duration
+	}{
+		{"weighted request", 0, 4, true, 6, 0},
+		{"another one", 0, 4, true, 2, 0},
+		{"not enough tokens", 0, 4, false, 2, time.Second},
+		{"refilled", time.Second, 4, true, 0, 0},
+		{"cost above burst waits for a full bucket", time.Second, 25, false, 0, 5 * time.Second},
+		{"cost above burst is capped on a full bucket", 6 * time.Second, 25, true, 0, 0},
+		{"unit cost after that", 6 * time.Second, 1, false, 0, 500 * time.Millisecond},
+	}
+	for _, s := range steps {
+		d := b.Take(start.Add(s.after), s.cost)
+		if d.Allowed != s.allowed || d.Remaining != s.remaining || d.RetryAfter != s.retryAfter {
+			t.Errorf("%s: allowed %v remaining %d retry after %s, want %v %d %s",
+				s.name, d.Allowed, d.Remaining, d.RetryAfter, s.allowed, s.remaining, s.retryAfter)
+		}
+		if d.Limit != 10 || d.Window != 5*time.Second {
+			t.Errorf("%s: limit %d window %s, want 10 and 5s", s.name, d.Limit, d.Window)
+		}
+	}
+}
+
+func TestBucketRetryAfterIsEnough(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := NewBucket(3, 1, start)
+	b.Take(start, 1)
+	now := start
+	for range 5 {
+		d := b.Take(now, 1)
+		if d.Allowed {
+			t.Fatal("empty bucket admitted a request")
+		}
+		now = now.Add(d.RetryAfter)
+		if d = b.Take(now, 1); !d.Allowed {
+			t.Fatalf("refused after waiting RetryAfter %s", d.RetryAfter)
+		}
+	}
+}
+
+func TestBucketDisabled(t *testing.T) {
+	b := NewBucket(0, 1, time.Now())
+	for range 3 {
+		if d := b.Take(time.Now(), 100); !d.Allowed || d.Limit != 0 {
+			t.Fatalf("disabled bucket: %+v", d)
+		}
+	}
+}
+
+func TestKeyedSweepsIdleBuckets(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	k := NewKeyed(1, 100)
+	k.now = func() time.Time { return now }
+	k.lastSweep = now
+
+	k.Take("idle", 1)
+	k.Take("drained", 100)
+	if d := k.Take("drained", 1); d.Allowed {
+		t.Fatal("drained key admitted a request")
+	}
+	if d := k.Take("other", 1); !d.Allowed {
+		t.Fatal("keys share a bucket")
+	}
+
+	// idle and other refill within a sweep interval; drained needs 100 seconds.
+	now = now.Add(sweepInterval)
+	k.Take("new", 1)
+	for key, kept := range map[string]bool{"idle": false, "other": false, "drained": true, "new": true} {
+		if _, ok := k.buckets[key]; ok != kept {
+			t.Errorf("bucket of %s kept = %v, want %v", key, ok, kept)
+		}
+	}
+}